@@ -1,10 +1,18 @@
 package main
 
 import (
-	"byto/internal/builder"
+	"bytes"
+	"byto/internal/api"
+	"byto/internal/archive"
+	"byto/internal/auth"
+	"byto/internal/clipboard"
 	"byto/internal/command"
 	"byto/internal/domain"
+	"byto/internal/downloader"
+	"byto/internal/history"
+	"byto/internal/manager"
 	"byto/internal/queue"
+	"byto/internal/scheduler"
 	"byto/internal/updater"
 	"context"
 	"fmt"
@@ -13,6 +21,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	goRuntime "runtime"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -24,20 +34,87 @@ type App struct {
 	settings      *domain.Setting
 	mediaDefaults *domain.MediaDefaults
 	updater       *updater.Updater
+	archive       *archive.Archive
+	authProfiles  *auth.Store
+	history       *history.Store
+	downloader    *downloader.Downloader
+	// broker relays download events to the control API's event stream
+	broker    *api.Broker
+	apiServer *api.Server
+	scheduler *scheduler.Scheduler
+	manager   *manager.Manager
+	clipboard *clipboard.Watcher
 }
 
 func NewApp() *App {
-	return &App{
-		queue:         queue.NewQueue(),
-		settings:      domain.NewSetting(),
+	settings := domain.NewSetting()
+	updater := updater.NewUpdater()
+	if err := updater.ConfigureNetwork(settings.Network); err != nil {
+		log.Printf("Error applying network settings: %v", err)
+	}
+	archive := archive.NewArchive()
+	authProfiles := auth.NewStore()
+	a := &App{
+		queue:         queue.NewPersistentQueue(queue.NewJournal()),
+		settings:      settings,
 		mediaDefaults: domain.NewMediaDefaults(),
-		updater:       updater.NewUpdater(),
+		updater:       updater,
+		archive:       archive,
+		authProfiles:  authProfiles,
+		history:       history.NewStore(),
+		downloader:    downloader.NewDownloader(settings, updater, archive, authProfiles),
+		broker:        api.NewBroker(),
 	}
+	a.manager = manager.NewManager(a.queue, settings.ParallelDownloads, a.download)
+	a.manager.SetSiteLimits(settings.SiteLimits)
+	a.scheduler = scheduler.NewScheduler(appScheduling{a}, func() domain.DownloadWindow {
+		return a.settings.CurrentDownloadWindow()
+	})
+	a.clipboard = clipboard.NewWatcher(a.readClipboard, clipboard.SiteMatcher{Sites: settings.Clipboard.WatchedSites()}, a.isKnownURL, a.clipboardLinkFound)
+	return a
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	log.Println("Byto App started")
+	a.resumeScheduledRetries()
+	a.scheduler.Start()
+	if a.settings.API.Enabled {
+		if err := a.startAPIServer(); err != nil {
+			log.Printf("Error starting API server: %v", err)
+		}
+	}
+	if a.settings.Clipboard.Enabled {
+		a.clipboard.Start()
+	}
+}
+
+// resumeScheduledRetries re-arms the retries that were pending when byto
+// was closed. Overdue ones run right away.
+func (a *App) resumeScheduledRetries() {
+	for _, media := range a.queue.GetAll() {
+		at, ok := media.RetryScheduledAt()
+		if !ok || media.Status != domain.Failed {
+			continue
+		}
+		id := media.ID
+		time.AfterFunc(time.Until(at), func() { a.retryDownload(id, at) })
+	}
+}
+
+func (a *App) shutdown(ctx context.Context) {
+	// Nothing may start downloads again while they are paused
+	a.scheduler.Stop()
+	a.clipboard.Stop()
+	a.stopAPIServer()
+
+	// Stop yt-dlp so running items are saved as paused and resume on the
+	// next start
+	a.PauseDownloads()
+	a.manager.Wait()
+
+	log.Println("Saving queue before exit")
+	a.queue.Persist()
 }
 
 func (a *App) Greet(name string) string {
@@ -50,9 +127,145 @@ func (a *App) GetSettings() *domain.Setting {
 
 func (a *App) UpdateSettings(parallelDownloads int) {
 	a.settings.Update(parallelDownloads)
+	a.manager.Resize(parallelDownloads)
 	log.Printf("Settings updated in memory: parallel=%d", parallelDownloads)
 }
 
+// UpdateDownloadArchiveSetting turns skipping already-downloaded videos on
+// or off
+func (a *App) UpdateDownloadArchiveSetting(enabled bool) {
+	a.settings.UpdateDownloadArchive(enabled)
+	log.Printf("Settings updated in memory: download archive=%v", enabled)
+}
+
+// UpdateRetryPolicy changes how failed downloads are retried
+func (a *App) UpdateRetryPolicy(policy domain.RetryPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	a.settings.UpdateRetryPolicy(policy)
+	log.Printf("Settings updated in memory: retry=%+v", policy)
+	return nil
+}
+
+// UpdateRateLimit changes the global download rate limit in bytes per
+// second, 0 for unlimited. Running downloads are rebalanced right away.
+func (a *App) UpdateRateLimit(bytesPerSecond int64) error {
+	if bytesPerSecond < 0 {
+		return fmt.Errorf("rate limit must not be negative, got %d", bytesPerSecond)
+	}
+	a.settings.UpdateRateLimit(bytesPerSecond)
+	a.downloader.Bandwidth.SetLimit(bytesPerSecond)
+	log.Printf("Settings updated in memory: rate limit=%s", domain.FormatRate(bytesPerSecond))
+	return nil
+}
+
+// UpdateDownloadWindow changes the hours downloads may run in. Running
+// downloads are paused right away when the new window is closed.
+func (a *App) UpdateDownloadWindow(window domain.DownloadWindow) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+	a.settings.UpdateDownloadWindow(window)
+	log.Printf("Settings updated in memory: download window=%+v", window)
+	a.scheduler.Kick()
+	return nil
+}
+
+// UpdateSiteLimits changes how many downloads of single sites may run at
+// once. Downloads over a lowered limit keep running.
+func (a *App) UpdateSiteLimits(limits domain.SiteLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	a.settings.UpdateSiteLimits(limits)
+	a.manager.SetSiteLimits(limits)
+	log.Printf("Settings updated in memory: site limits=%+v", limits)
+	return nil
+}
+
+// UpdateClipboardSettings turns the clipboard watcher on or off and changes
+// which links it picks up
+func (a *App) UpdateClipboardSettings(settings domain.ClipboardSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	a.settings.UpdateClipboard(settings)
+	a.clipboard.SetMatcher(clipboard.SiteMatcher{Sites: settings.WatchedSites()})
+	if settings.Enabled {
+		a.clipboard.Start()
+	} else {
+		a.clipboard.Stop()
+	}
+	log.Printf("Settings updated in memory: clipboard=%+v", settings)
+	return nil
+}
+
+// UpdateNetworkSettings changes the proxy and connection options used by
+// yt-dlp and the updater. yt-dlp picks them up on its next run.
+func (a *App) UpdateNetworkSettings(network domain.NetworkSettings) error {
+	if err := network.Validate(); err != nil {
+		return err
+	}
+	if err := a.updater.ConfigureNetwork(network); err != nil {
+		return err
+	}
+	a.settings.UpdateNetwork(network)
+	log.Printf("Settings updated in memory: proxy=%q source=%q ip=%q timeout=%d", network.RedactedProxy(), network.SourceAddress, network.IPVersion, network.SocketTimeoutSeconds)
+	return nil
+}
+
+// TestConnection checks whether the internet can be reached with the given
+// network settings, before they are saved
+func (a *App) TestConnection(network domain.NetworkSettings) updater.ConnectionResult {
+	log.Println("Testing connection")
+	return a.updater.TestConnection(network)
+}
+
+// UpdateAPISettings changes the local control API configuration and starts,
+// restarts or stops the server to match. Enabling the API without a token
+// generates one.
+func (a *App) UpdateAPISettings(enabled bool, port int, token string) (domain.APISettings, error) {
+	settings := domain.APISettings{Enabled: enabled, Port: port, Token: token}
+	if settings.Enabled && settings.Token == "" {
+		settings.Token = api.GenerateToken()
+	}
+	if err := settings.Validate(); err != nil {
+		return a.settings.API, err
+	}
+
+	a.stopAPIServer()
+	a.settings.UpdateAPI(settings)
+	log.Printf("Settings updated in memory: api enabled=%v port=%d", settings.Enabled, settings.Port)
+	if settings.Enabled {
+		if err := a.startAPIServer(); err != nil {
+			return settings, fmt.Errorf("starting API server: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+func (a *App) startAPIServer() error {
+	server := api.NewServer(a, a.broker, a.settings.API.Token)
+	if err := server.Start(a.settings.API.Port); err != nil {
+		return err
+	}
+	a.apiServer = server
+	return nil
+}
+
+func (a *App) stopAPIServer() {
+	if a.apiServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.apiServer.Stop(ctx); err != nil {
+		log.Printf("Error stopping API server: %v", err)
+	}
+	a.apiServer = nil
+}
+
 func (a *App) SaveSettings() error {
 	log.Println("Saving settings to file")
 	return a.settings.Save()
@@ -106,6 +319,10 @@ func (a *App) ShowInFolder(filePath string) {
 	case "darwin":
 		cmd = exec.Command("open", "-R", filePath)
 	default: // Linux
+		// xdg-open would open a file itself, so open its folder instead
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			filePath = filepath.Dir(filePath)
+		}
 		cmd = exec.Command("xdg-open", filePath)
 	}
 	if err := cmd.Start(); err != nil {
@@ -113,6 +330,52 @@ func (a *App) ShowInFolder(filePath string) {
 	}
 }
 
+// RevealMedia shows an item's downloaded file in the file manager, or its
+// download folder when no file was recorded or the file is gone
+func (a *App) RevealMedia(id string) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	path := media.FilePath
+	for _, file := range media.OutputFiles {
+		if _, err := os.Stat(file); err == nil {
+			path = file
+			break
+		}
+	}
+	a.ShowInFolder(path)
+	return nil
+}
+
+// OpenMediaFile opens one of an item's downloaded files with the default
+// application, e.g. to play it. index selects the file of a playlist item.
+func (a *App) OpenMediaFile(id string, index int) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(media.OutputFiles) {
+		return fmt.Errorf("item %s has no output file %d", id, index)
+	}
+	file := media.OutputFiles[index]
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+
+	log.Printf("Opening file: %s", file)
+	var cmd *exec.Cmd
+	switch goRuntime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", file)
+	case "darwin":
+		cmd = exec.Command("open", file)
+	default: // Linux
+		cmd = exec.Command("xdg-open", file)
+	}
+	return cmd.Start()
+}
+
 func (a *App) GetDefaultDownloadPath() string {
 	if a.mediaDefaults != nil {
 		return a.mediaDefaults.DownloadPath
@@ -127,27 +390,50 @@ func (a *App) GetMediaDefaults() *domain.MediaDefaults {
 
 // UpdateMediaDefaults updates the media defaults for new items
 func (a *App) UpdateMediaDefaults(quality string, downloadPath string, onlyAudio bool) {
-	var q domain.VideoQuality
-	switch quality {
-	case "360p":
-		q = domain.Quality360p
-	case "480p":
-		q = domain.Quality480p
-	case "720p":
-		q = domain.Quality720p
-	case "1080p":
-		q = domain.Quality1080p
-	case "1440p":
-		q = domain.Quality1440p
-	case "2160p":
-		q = domain.Quality2160p
-	default:
-		q = domain.Quality1080p
-	}
+	q := domain.ParseVideoQuality(quality)
 	a.mediaDefaults.Update(q, downloadPath, onlyAudio)
 	log.Printf("Media defaults updated in memory: quality=%s, path=%s, onlyAudio=%v", quality, downloadPath, onlyAudio)
 }
 
+// UpdateSubtitleDefaults updates the subtitle options applied to new items
+func (a *App) UpdateSubtitleDefaults(subtitles domain.SubtitleOptions) error {
+	if err := subtitles.Validate(); err != nil {
+		return err
+	}
+	a.mediaDefaults.UpdateSubtitles(subtitles)
+	log.Printf("Subtitle defaults updated in memory: %s", subtitles.Describe())
+	return nil
+}
+
+// UpdateOutputTemplateDefault updates the file name template applied to new
+// items. An empty template restores the built-in default.
+func (a *App) UpdateOutputTemplateDefault(template string) error {
+	if err := domain.ValidateOutputTemplate(template); err != nil {
+		return err
+	}
+	a.mediaDefaults.UpdateOutputTemplate(template)
+	log.Printf("Output template default updated in memory: %q", template)
+	return nil
+}
+
+// UpdateAudioDefaults updates the audio codec and bitrate applied to new
+// audio-only items
+func (a *App) UpdateAudioDefaults(audio domain.AudioProfile) error {
+	if err := audio.Validate(); err != nil {
+		return err
+	}
+	a.mediaDefaults.UpdateAudio(audio)
+	log.Printf("Audio defaults updated in memory: %+v", audio)
+	return nil
+}
+
+// UpdateEmbedDefaults updates the thumbnail, metadata and chapter embedding
+// applied to new items
+func (a *App) UpdateEmbedDefaults(embed domain.EmbedOptions) {
+	a.mediaDefaults.UpdateEmbed(embed)
+	log.Printf("Embed defaults updated in memory: %+v", embed)
+}
+
 // SaveMediaDefaults saves the media defaults to file
 func (a *App) SaveMediaDefaults() error {
 	log.Println("Saving media defaults to file")
@@ -158,52 +444,434 @@ func (a *App) AddToQueue(url string, quality string, customPath string, onlyAudi
 	id := uuid.New().String()
 	log.Printf("Adding to queue: %s with id: %s", url, id)
 
-	filePath := a.mediaDefaults.DownloadPath
+	media := a.mediaDefaults.NewMedia(id, url)
 	if customPath != "" {
-		filePath = customPath
-	}
-
-	// Convert quality string to VideoQuality
-	var q domain.VideoQuality
-	switch quality {
-	case "360p":
-		q = domain.Quality360p
-	case "480p":
-		q = domain.Quality480p
-	case "720p":
-		q = domain.Quality720p
-	case "1080p":
-		q = domain.Quality1080p
-	case "1440p":
-		q = domain.Quality1440p
-	case "2160p":
-		q = domain.Quality2160p
-	default:
-		q = domain.Quality1080p
-	}
-
-	a.queue.Add(&domain.Media{
-		ID:                id,
-		URL:               url,
-		Title:             "Pending...",
-		FilePath:          filePath,
-		Quality:           q,
-		OnlyAudio:         onlyAudio,
-		Status:            domain.Pending,
-		IsPlaylist:        isPlaylist,
-		PlaylistSelection: playlistSelection,
-		Progress: domain.DownloadProgress{
-			Percentage:      0,
-			DownloadedBytes: 0,
-			Logs:            []string{},
+		media.FilePath = customPath
+	}
+	// An empty quality keeps the default one
+	if quality != "" {
+		media.Quality = domain.ParseVideoQuality(quality)
+	}
+	media.OnlyAudio = onlyAudio
+	media.IsPlaylist = isPlaylist
+	media.PlaylistSelection = playlistSelection
+	// Started downloads pick the item up, but only once its metadata is in
+	a.manager.Hold(id)
+	a.queue.Add(media)
+	go func() {
+		defer a.manager.Unhold(id)
+		a.probeMedia(media)
+	}()
+	return id
+}
+
+// probeMedia fetches the item's metadata in the background so the real title,
+// thumbnail and playlist flag show up before the download starts.
+func (a *App) probeMedia(media *domain.Media) {
+	cmd := &command.ProbeCommand{
+		Builder: a.downloader.NewProbeBuilder(media),
+	}
+	if err := cmd.Execute(media); err != nil {
+		log.Printf("Metadata probe failed for %s: %v", media.URL, err)
+		media.AppendLog(fmt.Sprintf("Metadata probe failed: %v", err))
+		return
+	}
+
+	a.queue.Persist()
+	a.emitDownloadEvent("download_metadata", map[string]interface{}{
+		"id":       media.ID,
+		"metadata": cmd.Info,
+	})
+}
+
+// GetAvailableFormats probes a URL and returns the formats the site offers,
+// so the user can pick an exact one before or after adding it to the queue.
+func (a *App) GetAvailableFormats(url string) ([]domain.FormatInfo, error) {
+	log.Printf("Probing formats for: %s", url)
+	media := &domain.Media{URL: url}
+	cmd := &command.ProbeCommand{
+		Builder: a.downloader.NewProbeBuilder(media),
+	}
+	if err := cmd.Execute(media); err != nil {
+		log.Printf("Error probing formats: %v", err)
+		return nil, err
+	}
+	return cmd.Info.Formats, nil
+}
+
+// SetFormatSelection changes the format picked for a queued item. It cannot
+// be changed while the item is downloading.
+func (a *App) SetFormatSelection(id string, selection domain.FormatSelection) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := selection.Validate(); err != nil {
+		return err
+	}
+	if err := media.SetFormat(selection); err != nil {
+		return fmt.Errorf("cannot change the format: %w", err)
+	}
+	log.Printf("Format selection for %s set to %+v", id, selection)
+	return a.queue.Persist()
+}
+
+// SetSubtitleOptions changes the subtitle options of a queued item. They
+// cannot be changed while the item is downloading.
+func (a *App) SetSubtitleOptions(id string, subtitles domain.SubtitleOptions) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := subtitles.Validate(); err != nil {
+		return err
+	}
+	if err := media.SetSubtitles(subtitles); err != nil {
+		return fmt.Errorf("cannot change subtitles: %w", err)
+	}
+	log.Printf("Subtitles for %s set to %s", id, subtitles.Describe())
+	return a.queue.Persist()
+}
+
+// SetOutputTemplate overrides the file name template of a queued item. An
+// empty template uses the default.
+func (a *App) SetOutputTemplate(id string, template string) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := domain.ValidateOutputTemplate(template); err != nil {
+		return err
+	}
+	if err := media.SetOutputTemplate(template); err != nil {
+		return fmt.Errorf("cannot change the file name: %w", err)
+	}
+	log.Printf("Output template for %s set to %q", id, template)
+	return a.queue.Persist()
+}
+
+// PreviewOutputTemplate renders a template with the item's probed metadata
+// so the user can see the resulting path before downloading. An empty
+// template previews the one the item would currently use.
+func (a *App) PreviewOutputTemplate(id string, template string) (string, error) {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if template == "" {
+		template = media.ResolvedOutputTemplate()
+	}
+
+	name, err := domain.RenderOutputTemplate(domain.NormalizeOutputTemplate(template), media.TemplateFields())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(media.FilePath, name), nil
+}
+
+// SetAudioProfile changes the audio codec and bitrate of a queued item. It
+// cannot be changed while the item is downloading.
+func (a *App) SetAudioProfile(id string, audio domain.AudioProfile) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := audio.Validate(); err != nil {
+		return err
+	}
+	if err := media.SetAudio(audio); err != nil {
+		return fmt.Errorf("cannot change the audio format: %w", err)
+	}
+	log.Printf("Audio profile for %s set to %+v", id, audio)
+	return a.queue.Persist()
+}
+
+// SetEmbedOptions changes what gets embedded into a queued item's output
+// file. It cannot be changed while the item is downloading.
+func (a *App) SetEmbedOptions(id string, embed domain.EmbedOptions) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := media.SetEmbed(embed); err != nil {
+		return fmt.Errorf("cannot change embed options: %w", err)
+	}
+	log.Printf("Embed options for %s set to %+v", id, embed)
+	return a.queue.Persist()
+}
+
+// SetAuthProfile picks the authentication profile a queued item signs in
+// with. An empty profile id falls back to the profile for the item's site.
+// It cannot be changed while the item is downloading.
+func (a *App) SetAuthProfile(id string, profileID string) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if profileID != "" {
+		if _, err := a.authProfiles.Get(profileID); err != nil {
+			return err
+		}
+	}
+	if err := media.SetAuthProfileID(profileID); err != nil {
+		return fmt.Errorf("cannot change the authentication profile: %w", err)
+	}
+	log.Printf("Auth profile for %s set to %q", id, profileID)
+	return a.queue.Persist()
+}
+
+// SetRateLimit gives a queued item its own rate limit in bytes per second
+// instead of a share of the global one; 0 goes back to the share. It cannot
+// be changed while the item is downloading.
+func (a *App) SetRateLimit(id string, bytesPerSecond int64) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if bytesPerSecond < 0 {
+		return fmt.Errorf("rate limit must not be negative, got %d", bytesPerSecond)
+	}
+	if err := media.SetRateLimit(bytesPerSecond); err != nil {
+		return fmt.Errorf("cannot change the rate limit: %w", err)
+	}
+	log.Printf("Rate limit for %s set to %s", id, domain.FormatRate(bytesPerSecond))
+	return a.queue.Persist()
+}
+
+// SetStartAfter holds a queued item back until the given time; nil lets it
+// start with the next batch. It cannot be changed while the item is
+// downloading.
+func (a *App) SetStartAfter(id string, at *time.Time) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := media.SetStartAfter(at); err != nil {
+		return fmt.Errorf("cannot change the start time: %w", err)
+	}
+	a.attachCallbacks(media)
+	if at != nil && at.After(time.Now()) && media.CurrentStatus() == domain.Pending {
+		a.holdForSchedule(media, time.Now())
+	}
+	log.Printf("Start time for %s set to %v", id, at)
+	a.scheduler.Kick()
+	return a.queue.Persist()
+}
+
+// GetAuthProfiles returns the saved authentication profiles
+func (a *App) GetAuthProfiles() ([]domain.AuthProfile, error) {
+	return a.authProfiles.Profiles()
+}
+
+// GetCookieBrowsers returns the browsers cookies can be read from
+func (a *App) GetCookieBrowsers() []string {
+	return domain.CookieBrowsers
+}
+
+// SaveAuthProfile adds or updates an authentication profile and returns it
+// with its id
+func (a *App) SaveAuthProfile(profile domain.AuthProfile) (domain.AuthProfile, error) {
+	log.Printf("Saving auth profile: %s", profile.Name)
+	return a.authProfiles.Save(profile)
+}
+
+// DeleteAuthProfile removes an authentication profile. Queued items that
+// used it fall back to the profile for their site.
+func (a *App) DeleteAuthProfile(id string) error {
+	log.Printf("Deleting auth profile: %s", id)
+	if err := a.authProfiles.Remove(id); err != nil {
+		return err
+	}
+	for _, media := range a.queue.GetAll() {
+		media.ForgetAuthProfile(id)
+	}
+	return a.queue.Persist()
+}
+
+// SelectCookieFile lets the user pick a Netscape cookie file
+func (a *App) SelectCookieFile() string {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Cookie File",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Cookie files (*.txt)", Pattern: "*.txt"},
 		},
 	})
-	return id
+	if err != nil {
+		log.Printf("Error selecting cookie file: %v", err)
+		return ""
+	}
+	return path
+}
+
+// GetArchiveEntries returns the videos recorded in the download archive
+func (a *App) GetArchiveEntries() ([]archive.Entry, error) {
+	return a.archive.Entries()
+}
+
+// SearchArchive returns the archive entries whose site or video id matches
+// the query
+func (a *App) SearchArchive(query string) ([]archive.Entry, error) {
+	return a.archive.Search(query)
+}
+
+// RemoveArchiveEntries prunes entries from the download archive so those
+// videos are downloaded again
+func (a *App) RemoveArchiveEntries(entries []archive.Entry) (int, error) {
+	removed, err := a.archive.Remove(entries)
+	if err != nil {
+		log.Printf("Error pruning download archive: %v", err)
+		return 0, err
+	}
+	log.Printf("Removed %d entries from the download archive", removed)
+	return removed, nil
+}
+
+// ClearArchive removes every entry from the download archive
+func (a *App) ClearArchive() error {
+	log.Println("Clearing download archive")
+	return a.archive.Clear()
+}
+
+// readClipboard returns the text on the clipboard
+func (a *App) readClipboard() (string, error) {
+	return runtime.ClipboardGetText(a.ctx)
+}
+
+// isKnownURL reports whether a URL is already queued or in the history
+func (a *App) isKnownURL(url string) bool {
+	for _, media := range a.queue.GetAll() {
+		if media.URL == url {
+			return true
+		}
+	}
+	found, err := a.history.HasURL(url)
+	if err != nil {
+		log.Printf("Error checking the history for %s: %v", url, err)
+	}
+	return found
+}
+
+// clipboardLinkFound queues a copied link with the media defaults when
+// auto-add is on, and otherwise offers it to the frontend
+func (a *App) clipboardLinkFound(url string) {
+	if !a.settings.CurrentClipboard().AutoAdd {
+		runtime.EventsEmit(a.ctx, "clipboard_link", map[string]interface{}{
+			"url": url,
+		})
+		return
+	}
+
+	id := a.AddToQueue(url, a.mediaDefaults.Quality.String(), "", a.mediaDefaults.OnlyAudio, false, domain.PlaylistSelection{})
+	runtime.EventsEmit(a.ctx, "clipboard_added", map[string]interface{}{
+		"id":  id,
+		"url": url,
+	})
+}
+
+// GetHistory returns every finished download, newest first
+func (a *App) GetHistory() ([]history.Entry, error) {
+	return a.history.Entries()
+}
+
+// SearchHistory returns the finished downloads passing the filter, newest
+// first
+func (a *App) SearchHistory(filter history.Filter) ([]history.Entry, error) {
+	return a.history.Search(filter)
+}
+
+// ExportHistory saves the finished downloads passing the filter as "csv" or
+// "json" to a file the user picks. It returns the file's path, or an empty
+// string when the user cancelled.
+func (a *App) ExportHistory(filter history.Filter, format string) (string, error) {
+	entries, err := a.history.Search(filter)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := history.Export(&buf, entries, format); err != nil {
+		return "", err
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export History",
+		DefaultFilename: "byto-history." + format,
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(format) + " files (*." + format + ")", Pattern: "*." + format},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	log.Printf("Exported %d history entries to %s", len(entries), path)
+	return path, nil
+}
+
+// RequeueFromHistory adds a finished download to the queue again with the
+// options it was downloaded with, and returns the new item's id
+func (a *App) RequeueFromHistory(entryID string) (string, error) {
+	entry, err := a.history.Get(entryID)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	log.Printf("Re-queueing %s from history with id: %s", entry.URL, id)
+	media := entry.NewMedia(id)
+	if media.AuthProfileID != "" {
+		// The profile may have been deleted since
+		if _, err := a.authProfiles.Get(media.AuthProfileID); err != nil {
+			media.AuthProfileID = ""
+		}
+	}
+	a.manager.Hold(id)
+	a.queue.Add(media)
+	go func() {
+		defer a.manager.Unhold(id)
+		a.probeMedia(media)
+	}()
+	return id, nil
+}
+
+// DeleteHistoryEntry removes one entry from the history
+func (a *App) DeleteHistoryEntry(id string) error {
+	log.Printf("Deleting history entry: %s", id)
+	return a.history.Remove(id)
+}
+
+// ClearHistory removes every entry from the history
+func (a *App) ClearHistory() error {
+	log.Println("Clearing download history")
+	return a.history.Clear()
+}
+
+// recordHistory adds a finished item to the history. Failed items are only
+// recorded once no retry is left.
+func (a *App) recordHistory(m *domain.Media) {
+	if !m.CurrentStatus().IsFinished() {
+		return
+	}
+	if _, retrying := m.RetryScheduledAt(); retrying {
+		return
+	}
+	entry, err := a.history.Record(m)
+	if err != nil {
+		log.Printf("Error recording %s in the history: %v", m.URL, err)
+		return
+	}
+	runtime.EventsEmit(a.ctx, "history_added", entry)
 }
 
 func (a *App) RemoveFromQueue(id string) error {
 	log.Printf("Removing from queue: %s", id)
-	a.PauseSingleDownload(id)
+	if err := a.PauseSingleDownload(id); err != nil {
+		return err
+	}
+	// Let yt-dlp and ffmpeg exit first so nothing is written for the item
+	// once it is gone
+	a.manager.WaitFor(id)
 	return a.queue.Remove(id)
 }
 
@@ -211,145 +879,193 @@ func (a *App) GetQueue() []*domain.Media {
 	return a.queue.GetAll()
 }
 
-func (a *App) StartDownloads() {
-	log.Println("Starting downloads")
-	if a.settings == nil {
-		a.settings = domain.NewSetting()
+// MoveInQueue moves an item to the given position in the queue. Among
+// items of the same priority, earlier ones download first.
+func (a *App) MoveInQueue(id string, index int) error {
+	log.Printf("Moving %s to position %d", id, index)
+	return a.queue.Move(id, index)
+}
+
+func (a *App) MoveToTop(id string) error {
+	log.Printf("Moving %s to the top of the queue", id)
+	return a.queue.MoveToTop(id)
+}
+
+func (a *App) MoveToBottom(id string) error {
+	log.Printf("Moving %s to the bottom of the queue", id)
+	return a.queue.MoveToBottom(id)
+}
+
+// SetPriority changes an item's priority. Higher priority items start
+// before the rest of the queue.
+func (a *App) SetPriority(id string, priority domain.Priority) error {
+	media, err := a.queue.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := priority.Validate(); err != nil {
+		return err
 	}
 
-	queueItems := a.queue.GetAll()
-	semaphore := make(chan struct{}, a.settings.ParallelDownloads)
+	media.SetPriority(priority)
+	log.Printf("Priority for %s set to %s", id, priority)
+	return a.queue.Persist()
+}
 
-	// Collect pending/failed/paused items in order
-	var pendingItems []*domain.Media
-	for _, media := range queueItems {
+func (a *App) StartDownloads() {
+	log.Println("Starting downloads")
+
+	// Collect pending/failed/paused items; the manager starts them by
+	// priority and queue position, and keeps picking up items added later
+	var items []*domain.Media
+	for _, media := range a.queue.GetAll() {
 		if media.Status == domain.Pending || media.Status == domain.Failed || media.Status == domain.Paused {
-			// Create a context for cancellation
-			ctx, cancelFunc := context.WithCancel(context.Background())
-			media.Ctx = ctx
-			media.CancelFunc = cancelFunc
-
-			// Attach callbacks - capture the media ID to avoid closure issues
-			mediaID := media.ID
-			media.OnProgress = func(id string, progress domain.DownloadProgress) {
-				// Get the current media state to include title
-				currentMedia, err := a.queue.Get(id)
-				title := "Pending..."
-				totalBytes := int64(0)
-				if err == nil && currentMedia != nil {
-					title = currentMedia.Title
-					totalBytes = currentMedia.TotalBytes
-				}
-				runtime.EventsEmit(a.ctx, "download_progress", map[string]interface{}{
-					"id":          id,
-					"title":       title,
-					"total_bytes": totalBytes,
-					"progress":    progress,
-				})
+			if a.manager.IsRunning(media.ID) {
+				continue
 			}
+			media.ResetAttempts()
+			items = append(items, media)
+		}
+	}
+	a.manager.Request(items...)
+	a.manager.Activate()
+}
 
-			media.OnStatusChange = func(id string, status domain.DownloadStatus) {
-				runtime.EventsEmit(a.ctx, "download_status", map[string]interface{}{
-					"id":     id,
-					"status": status,
-				})
-			}
+// download runs one item for the download manager. The global rate limit
+// is split between the running downloads and rebalanced by the downloader
+// as they start and finish.
+func (a *App) download(media *domain.Media) {
+	a.prepareDownload(media)
+	a.runDownload(media)
+}
 
-			media.OnTitleChange = func(id string, title string) {
-				runtime.EventsEmit(a.ctx, "download_title", map[string]interface{}{
-					"id":    id,
-					"title": title,
-				})
-			}
+// prepareDownload gives an item a fresh context for cancellation and wires
+// its callbacks
+func (a *App) prepareDownload(media *domain.Media) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	media.Ctx = ctx
+	media.CancelFunc = cancelFunc
+
+	a.attachCallbacks(media)
+}
 
-			pendingItems = append(pendingItems, media)
-			_ = mediaID // used in callbacks via closure
+// runDownload executes the download for a media item and schedules a retry
+// when it fails. Items that aren't due yet are left to the scheduler, as are
+// downloads it paused because the download window closed. An item started
+// by hand is only exempt from the schedule for this run; its retries wait
+// for the window again.
+func (a *App) runDownload(m *domain.Media) {
+	defer a.scheduler.ClearOverride(m.ID)
+	// Drop a pause request that came in after the last run had finished
+	a.scheduler.TakePaused(m.ID)
+	if now := time.Now(); !a.scheduler.Due(m, now) {
+		a.holdForSchedule(m, now)
+		return
+	}
+
+	err := a.downloader.Run(m)
+	switch {
+	case err == context.Canceled:
+		if a.scheduler.TakePaused(m.ID) {
+			m.AppendLog("[byto] Paused until the download window opens")
+			m.SetStatus(domain.Scheduled)
 		}
+	case err != nil:
+		a.scheduleRetry(m)
+		a.recordHistory(m)
+	default:
+		a.recordHistory(m)
 	}
+}
 
-	// Use a job channel to maintain FIFO order
-	jobs := make(chan *domain.Media, len(pendingItems))
-	for _, media := range pendingItems {
-		jobs <- media
-	}
-	close(jobs)
-
-	// Start workers that pull from the job channel in order
-	for i := 0; i < a.settings.ParallelDownloads; i++ {
-		go func() {
-			for m := range jobs {
-				semaphore <- struct{}{}
-
-				m.SetStatus(domain.InProgress)
-				log.Printf("Processing item: %s", m.URL)
-
-				// Initialize builder - use media's own FilePath and Quality
-				b := builder.NewYTDLPBuilder().
-					URL(m.URL).
-					DownloadPath(m.FilePath).
-					SafeFilenames()
-				if m.OnlyAudio {
-					b = b.Audio()
-				} else {
-					b = b.Video(m.Quality)
-				}
-				if m.IsPlaylist {
-					b = b.Playlist(m.PlaylistSelection)
-				}
-
-				cmd := &command.DownloadCommand{
-					Builder: b,
-				}
-
-				if err := cmd.Execute(m); err != nil {
-					if err == context.Canceled {
-						// Download was paused, set status to Paused
-						m.SetStatus(domain.Paused)
-						log.Printf("Download paused for %s", m.URL)
-					} else {
-						m.SetStatus(domain.Failed)
-						log.Printf("Download failed for %s: %v", m.URL, err)
-						m.AppendLog(fmt.Sprintf("Download failed: %v", err))
-					}
-				} else {
-					log.Printf("Download completed: %s", m.URL)
-				}
-
-				<-semaphore
-			}
-		}()
+// holdForSchedule marks an item as waiting for its start time or for the
+// download window
+func (a *App) holdForSchedule(m *domain.Media, now time.Time) {
+	window := a.settings.CurrentDownloadWindow()
+	at := window.NextOpen(now)
+	if startAfter := m.CurrentStartAfter(); startAfter != nil && startAfter.After(at) {
+		at = window.NextOpen(*startAfter)
 	}
+	log.Printf("Holding %s until %s", m.URL, at.Format(time.RFC1123))
+	m.AppendLog("[byto] Scheduled to start at " + at.Format("Mon 15:04"))
+	m.SetStatus(domain.Scheduled)
 }
 
-func (a *App) PauseDownloads() {
-	log.Println("Pausing all downloads")
-	queueItems := a.queue.GetAll()
+// appScheduling lets the scheduler start and pause downloads without
+// exposing those hooks to the frontend
+type appScheduling struct {
+	a *App
+}
 
-	for _, media := range queueItems {
-		if media.Status == domain.InProgress {
-			media.Cancel()
+func (s appScheduling) Items() []*domain.Media {
+	return s.a.queue.GetAll()
+}
+
+func (s appScheduling) Start(items []*domain.Media) {
+	// Leave the Scheduled status right away so the next check doesn't
+	// start them a second time. Partly downloaded items become Paused so
+	// they continue from their partial files.
+	for _, m := range items {
+		s.a.attachCallbacks(m)
+		if m.Progress.DownloadedBytes > 0 {
+			m.SetStatus(domain.Paused)
+		} else {
+			m.SetStatus(domain.Pending)
 		}
 	}
+	s.a.manager.Request(items...)
 }
 
-func (a *App) StartSingleDownload(id string) {
-	log.Printf("Starting single download: %s", id)
+func (s appScheduling) Pause(m *domain.Media) {
+	m.Cancel()
+}
+
+// scheduleRetry starts a failed item again after the retry policy's backoff
+// when its failure kind is worth retrying
+func (a *App) scheduleRetry(m *domain.Media) {
+	policy := a.settings.CurrentRetry()
+	if !policy.ShouldRetry(m.ErrorKind, m.Attempts) {
+		return
+	}
+
+	delay := policy.Delay(m.Attempts)
+	at := time.Now().Add(delay)
+	m.ScheduleRetry(at)
+	a.queue.Persist()
+	log.Printf("Retrying %s in %s (attempt %d of %d)", m.URL, delay, m.Attempts+1, policy.MaxAttempts)
+	m.AppendLog(fmt.Sprintf("[byto] Retrying in %s (attempt %d of %d)", delay, m.Attempts+1, policy.MaxAttempts))
+	time.AfterFunc(delay, func() { a.retryDownload(m.ID, at) })
+}
+
+// retryDownload queues a scheduled retry unless the item was removed, started
+// by hand or rescheduled in the meantime
+func (a *App) retryDownload(id string, at time.Time) {
 	media, err := a.queue.Get(id)
 	if err != nil {
-		log.Printf("Error getting media from queue: %v", err)
 		return
 	}
-
-	if media.Status != domain.Pending && media.Status != domain.Failed && media.Status != domain.Paused {
-		log.Printf("Media %s is not in a startable state (status: %d)", id, media.Status)
+	scheduled, ok := media.RetryScheduledAt()
+	if !ok || !scheduled.Equal(at) || media.Status != domain.Failed {
 		return
 	}
+	// Wait for a free worker like any other download, so retries after
+	// HTTP 429 errors respect the site limits
+	a.manager.Request(media)
+}
 
-	ctx, cancelFunc := context.WithCancel(context.Background())
-	media.Ctx = ctx
-	media.CancelFunc = cancelFunc
+// emitDownloadEvent sends a download event to the frontend and to clients
+// of the control API's event stream
+func (a *App) emitDownloadEvent(name string, payload map[string]interface{}) {
+	runtime.EventsEmit(a.ctx, name, payload)
+	a.broker.Publish(name, payload)
+}
 
+// attachCallbacks wires the media's progress, status, title, playlist entry
+// and retry updates to frontend events. Status and title changes are also
+// written to the queue journal so they survive a restart.
+func (a *App) attachCallbacks(media *domain.Media) {
 	media.OnProgress = func(id string, progress domain.DownloadProgress) {
+		// Get the current media state to include title
 		currentMedia, err := a.queue.Get(id)
 		title := "Pending..."
 		totalBytes := int64(0)
@@ -357,7 +1073,7 @@ func (a *App) StartSingleDownload(id string) {
 			title = currentMedia.Title
 			totalBytes = currentMedia.TotalBytes
 		}
-		runtime.EventsEmit(a.ctx, "download_progress", map[string]interface{}{
+		a.emitDownloadEvent("download_progress", map[string]interface{}{
 			"id":          id,
 			"title":       title,
 			"total_bytes": totalBytes,
@@ -366,66 +1082,107 @@ func (a *App) StartSingleDownload(id string) {
 	}
 
 	media.OnStatusChange = func(id string, status domain.DownloadStatus) {
-		runtime.EventsEmit(a.ctx, "download_status", map[string]interface{}{
+		a.queue.Persist()
+		payload := map[string]interface{}{
 			"id":     id,
 			"status": status,
-		})
+		}
+		if status == domain.Failed {
+			payload["error_kind"] = media.ErrorKind
+			payload["error_hint"] = media.ErrorHint
+		}
+		a.emitDownloadEvent("download_status", payload)
 	}
 
 	media.OnTitleChange = func(id string, title string) {
-		runtime.EventsEmit(a.ctx, "download_title", map[string]interface{}{
+		a.queue.Persist()
+		a.emitDownloadEvent("download_title", map[string]interface{}{
 			"id":    id,
 			"title": title,
 		})
 	}
 
-	go func() {
-		media.SetStatus(domain.InProgress)
-		log.Printf("Processing item: %s", media.URL)
-
-		b := builder.NewYTDLPBuilder().
-			URL(media.URL).
-			DownloadPath(media.FilePath).
-			SafeFilenames()
-		if media.OnlyAudio {
-			b = b.Audio()
-		} else {
-			b = b.Video(media.Quality)
-		}
-		if media.IsPlaylist {
-			b = b.Playlist(media.PlaylistSelection)
-		}
+	media.OnRetry = func(id string, attempts int, at time.Time) {
+		a.emitDownloadEvent("download_retry", map[string]interface{}{
+			"id":            id,
+			"attempts":      attempts,
+			"next_retry_at": at,
+		})
+	}
 
-		cmd := &command.DownloadCommand{
-			Builder: b,
-		}
+	media.OnEntryChange = func(id string, entry domain.PlaylistEntry) {
+		a.emitDownloadEvent("download_entry", map[string]interface{}{
+			"id":    id,
+			"entry": entry,
+		})
+	}
+}
 
-		if err := cmd.Execute(media); err != nil {
-			if err == context.Canceled {
-				media.SetStatus(domain.Paused)
-				log.Printf("Download paused for %s", media.URL)
-			} else {
-				media.SetStatus(domain.Failed)
-				log.Printf("Download failed for %s: %v", media.URL, err)
-				media.AppendLog(fmt.Sprintf("Download failed: %v", err))
-			}
-		} else {
-			log.Printf("Download completed: %s", media.URL)
+func (a *App) PauseDownloads() {
+	log.Println("Pausing all downloads")
+	// Stop starting queued items before pausing the running ones
+	a.manager.Deactivate()
+	queueItems := a.queue.GetAll()
+
+	for _, media := range queueItems {
+		switch status := media.CurrentStatus(); {
+		case status.IsRunning():
+			media.Cancel()
+		case status == domain.Failed:
+			// Drop any scheduled retry
+			media.ResetAttempts()
+		case status == domain.Scheduled:
+			media.SetStatus(domain.Paused)
 		}
-	}()
+	}
 }
 
-func (a *App) PauseSingleDownload(id string) {
-	log.Printf("Pausing single download: %s", id)
+func (a *App) StartSingleDownload(id string) {
+	log.Printf("Starting single download: %s", id)
 	media, err := a.queue.Get(id)
 	if err != nil {
 		log.Printf("Error getting media from queue: %v", err)
 		return
 	}
 
-	if media.Status == domain.InProgress {
+	switch status := media.CurrentStatus(); status {
+	case domain.Pending, domain.Failed, domain.Paused, domain.Scheduled:
+	default:
+		log.Printf("Media %s is not in a startable state (status: %d)", id, status)
+		return
+	}
+	if a.manager.IsRunning(id) {
+		log.Printf("Media %s is already being downloaded", id)
+		return
+	}
+
+	media.ResetAttempts()
+	// Started by hand, so it runs even outside the download window
+	a.scheduler.Override(id)
+	go a.manager.RunNow(media)
+}
+
+func (a *App) PauseSingleDownload(id string) error {
+	log.Printf("Pausing single download: %s", id)
+	media, err := a.queue.Get(id)
+	if err != nil {
+		log.Printf("Error getting media from queue: %v", err)
+		return err
+	}
+
+	a.manager.Withdraw(id)
+	switch {
+	case media.Status.IsRunning():
 		media.Cancel()
+	case media.Status == domain.Failed:
+		// Drop any scheduled retry
+		media.ResetAttempts()
+	case media.Status == domain.Pending, media.Status == domain.Scheduled:
+		// Keep the download manager from starting it
+		a.attachCallbacks(media)
+		media.SetStatus(domain.Paused)
 	}
+	return nil
 }
 
 func (a *App) GetAppVersion() string {
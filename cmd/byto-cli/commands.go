@@ -0,0 +1,418 @@
+package main
+
+import (
+	"byto/internal/archive"
+	"byto/internal/auth"
+	"byto/internal/command"
+	"byto/internal/domain"
+	"byto/internal/downloader"
+	"byto/internal/history"
+	"byto/internal/manager"
+	"byto/internal/queue"
+	"byto/internal/updater"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func openQueue() *queue.Queue {
+	return queue.NewPersistentQueue(queue.NewJournal())
+}
+
+// findMedia looks up an item by its id or a unique prefix of it
+func findMedia(q *queue.Queue, id string) (*domain.Media, error) {
+	if media, err := q.Get(id); err == nil {
+		return media, nil
+	}
+
+	var match *domain.Media
+	for _, media := range q.GetAll() {
+		if strings.HasPrefix(media.ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("id %q matches more than one item", id)
+			}
+			match = media
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no item with id %q", id)
+	}
+	return match, nil
+}
+
+func runAdd(args []string) error {
+	defaults := domain.NewMediaDefaults()
+
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	quality := fs.String("quality", defaults.Quality.String(), "maximum video quality, e.g. 720p")
+	path := fs.String("path", defaults.DownloadPath, "download folder")
+	onlyAudio := fs.Bool("audio", defaults.OnlyAudio, "download audio only")
+	items := fs.String("items", "", "playlist items to download, e.g. 1,3-5")
+	rate := fs.String("rate", "", "rate limit for these items, e.g. 500K or 2M; overrides their share of the global limit")
+	priority := fs.String("priority", "normal", "download priority: low, normal or high")
+	profileName := fs.String("profile", "", "authentication profile to sign in with, by name or id")
+	noProbe := fs.Bool("no-probe", false, "don't fetch the title and playlist info before adding")
+	jsonOut := fs.Bool("json", false, "print the added items as JSON lines")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("add needs at least one URL")
+	}
+
+	rateLimit, err := domain.ParseRate(*rate)
+	if err != nil {
+		return err
+	}
+	itemPriority, err := domain.ParsePriority(*priority)
+	if err != nil {
+		return err
+	}
+
+	profiles := auth.NewStore()
+	var profileID string
+	if *profileName != "" {
+		profile, err := profiles.Find(*profileName)
+		if err != nil {
+			return err
+		}
+		profileID = profile.ID
+	}
+
+	d := downloader.NewDownloader(domain.NewSetting(), nil, nil, profiles)
+	q := openQueue()
+	out := newPrinter(os.Stdout, *jsonOut)
+	for _, url := range fs.Args() {
+		media := defaults.NewMedia(uuid.New().String(), url)
+		media.FilePath = *path
+		media.Quality = domain.ParseVideoQuality(*quality)
+		media.OnlyAudio = *onlyAudio
+		media.AuthProfileID = profileID
+		media.RateLimit = rateLimit
+		media.Priority = itemPriority
+		if *items != "" {
+			media.IsPlaylist = true
+			media.PlaylistSelection = domain.PlaylistSelection{Type: domain.SelectionItems, Items: *items}
+		}
+
+		if !*noProbe {
+			cmd := &command.ProbeCommand{Builder: d.NewProbeBuilder(media)}
+			if err := cmd.Execute(media); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not fetch info for %s: %v\n", url, err)
+			}
+		}
+
+		q.Add(media)
+		out.added(media)
+	}
+	return nil
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "print items as JSON lines")
+	fs.Parse(args)
+
+	newPrinter(os.Stdout, *jsonOut).list(openQueue().GetAll())
+	return nil
+}
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "print the item as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("status needs exactly one id")
+	}
+	media, err := findMedia(openQueue(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newPrinter(os.Stdout, *jsonOut).status(media)
+	return nil
+}
+
+func runPause(args []string) error {
+	if len(args) == 0 {
+		return errors.New("pause needs at least one id")
+	}
+
+	q := openQueue()
+	for _, id := range args {
+		media, err := findMedia(q, id)
+		if err != nil {
+			return err
+		}
+		if media.Status != domain.Pending && media.Status != domain.Failed {
+			return fmt.Errorf("item %s is %s and can't be paused", media.ID, media.Status)
+		}
+		media.ResetAttempts()
+		media.SetStatus(domain.Paused)
+	}
+	return q.Persist()
+}
+
+func runMove(args []string) error {
+	if len(args) != 2 {
+		return errors.New("move needs an id and a position: top, bottom or a number starting at 1")
+	}
+
+	q := openQueue()
+	media, err := findMedia(q, args[0])
+	if err != nil {
+		return err
+	}
+	switch args[1] {
+	case "top":
+		return q.MoveToTop(media.ID)
+	case "bottom":
+		return q.MoveToBottom(media.ID)
+	default:
+		position, err := strconv.Atoi(args[1])
+		if err != nil || position < 1 {
+			return fmt.Errorf("invalid position %q", args[1])
+		}
+		return q.Move(media.ID, position-1)
+	}
+}
+
+func runPriority(args []string) error {
+	if len(args) < 2 {
+		return errors.New("priority needs a level and at least one id")
+	}
+	priority, err := domain.ParsePriority(args[0])
+	if err != nil {
+		return err
+	}
+
+	q := openQueue()
+	for _, id := range args[1:] {
+		media, err := findMedia(q, id)
+		if err != nil {
+			return err
+		}
+		media.SetPriority(priority)
+	}
+	return q.Persist()
+}
+
+func runRemove(args []string) error {
+	if len(args) == 0 {
+		return errors.New("remove needs at least one id")
+	}
+
+	q := openQueue()
+	for _, id := range args {
+		media, err := findMedia(q, id)
+		if err != nil {
+			return err
+		}
+		if err := q.Remove(media.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runStart(args []string) error {
+	fs := flag.NewFlagSet("start", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "print events as JSON lines")
+	all := fs.Bool("all", false, "also start paused items")
+	rate := fs.String("rate", "", "global rate limit for this run, e.g. 2M; defaults to the saved setting")
+	now := fs.Bool("now", false, "ignore start times and the download window")
+	fs.Parse(args)
+
+	settings := domain.NewSetting()
+	if *rate != "" {
+		rateLimit, err := domain.ParseRate(*rate)
+		if err != nil {
+			return err
+		}
+		settings.RateLimit = rateLimit
+	}
+
+	q := openQueue()
+	var items []*domain.Media
+	if fs.NArg() > 0 {
+		for _, id := range fs.Args() {
+			media, err := findMedia(q, id)
+			if err != nil {
+				return err
+			}
+			if media.Status != domain.Pending && media.Status != domain.Failed && media.Status != domain.Paused && media.Status != domain.Scheduled {
+				return fmt.Errorf("item %s is %s and can't be started", media.ID, media.Status)
+			}
+			items = append(items, media)
+		}
+	} else {
+		for _, media := range q.Ordered() {
+			if media.Status == domain.Pending || media.Status == domain.Failed || media.Status == domain.Scheduled || (*all && media.Status == domain.Paused) {
+				items = append(items, media)
+			}
+		}
+	}
+	if !*now {
+		items = dueItems(items, settings.DownloadWindow, time.Now())
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "nothing to download")
+		return nil
+	}
+
+	u := updater.NewUpdater()
+	if err := u.ConfigureNetwork(settings.Network); err != nil {
+		return fmt.Errorf("network settings: %w", err)
+	}
+	d := downloader.NewDownloader(settings, u, archive.NewArchive(), auth.NewStore())
+	h := history.NewStore()
+	out := newPrinter(os.Stdout, *jsonOut)
+
+	// Ctrl+C pauses the running downloads so they can be resumed later
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, media := range items {
+		mediaCtx, cancel := context.WithCancel(ctx)
+		media.Ctx = mediaCtx
+		media.CancelFunc = cancel
+		media.ResetAttempts()
+		out.attach(q, media)
+	}
+
+	workers := settings.ParallelDownloads
+	if workers < 1 {
+		workers = 1
+	}
+	var r *retries
+	m := manager.NewManager(q, workers, func(media *domain.Media) {
+		if ctx.Err() != nil {
+			return
+		}
+		download(d, q, h, out, r, media)
+	})
+	r = &retries{ctx: ctx, manager: m}
+	m.SetSiteLimits(settings.SiteLimits)
+	m.Request(items...)
+	// A retry due later requests its item again, which may fail and
+	// schedule another one
+	m.Wait()
+	for r.wait() {
+		m.Wait()
+	}
+	q.Persist()
+
+	failed := 0
+	for _, media := range items {
+		if media.Status == domain.Failed {
+			failed++
+		}
+	}
+	if ctx.Err() != nil {
+		return errors.New("interrupted, unfinished downloads were paused")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, len(items))
+	}
+	return nil
+}
+
+// dueItems drops the items whose start time hasn't come yet, or all of them
+// when the download window is closed
+func dueItems(items []*domain.Media, window domain.DownloadWindow, now time.Time) []*domain.Media {
+	if !window.IsOpen(now) {
+		fmt.Fprintf(os.Stderr, "the download window is closed until %s, use -now to start anyway\n", window.NextOpen(now).Format(time.Kitchen))
+		return nil
+	}
+
+	var due []*domain.Media
+	for _, media := range items {
+		if !media.StartDue(now) {
+			fmt.Fprintf(os.Stderr, "%s is scheduled for %s, skipping\n", shortID(media.ID), media.StartAfter.Format(time.RFC1123))
+			continue
+		}
+		due = append(due, media)
+	}
+	return due
+}
+
+// download runs one attempt of an item. Failures the retry policy covers
+// are requested again once their delay is over, so the item doesn't keep a
+// worker or its site's slot while it waits. Finished items are recorded in
+// the history.
+func download(d *downloader.Downloader, q *queue.Queue, h *history.Store, out *printer, r *retries, media *domain.Media) {
+	out.started(media)
+	err := d.Run(media)
+	out.outcome(media)
+	if err == context.Canceled {
+		return
+	}
+	if err == nil {
+		record(h, media)
+		return
+	}
+
+	policy := d.Settings.CurrentRetry()
+	if !policy.ShouldRetry(media.ErrorKind, media.Attempts) {
+		record(h, media)
+		return
+	}
+	delay := policy.Delay(media.Attempts)
+	media.ScheduleRetry(time.Now().Add(delay))
+	q.Persist()
+	r.schedule(media, delay)
+}
+
+// retries requests failed items from the manager again once their retry
+// delay is over
+type retries struct {
+	ctx     context.Context
+	manager *manager.Manager
+	waiting atomic.Int32
+	wg      sync.WaitGroup
+}
+
+// schedule requests the item again after the delay, unless the context is
+// cancelled first
+func (r *retries) schedule(media *domain.Media, delay time.Duration) {
+	r.waiting.Add(1)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.waiting.Add(-1)
+		select {
+		case <-r.ctx.Done():
+		case <-time.After(delay):
+			// Requested before Done, so the manager's Wait covers it
+			r.manager.Request(media)
+		}
+	}()
+}
+
+// wait blocks until no retry is waiting and reports whether there were any
+func (r *retries) wait() bool {
+	if r.waiting.Load() == 0 {
+		return false
+	}
+	r.wg.Wait()
+	return true
+}
+
+func record(h *history.Store, media *domain.Media) {
+	if !media.CurrentStatus().IsFinished() {
+		return
+	}
+	if _, err := h.Record(media); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record %s in the history: %v\n", shortID(media.ID), err)
+	}
+}
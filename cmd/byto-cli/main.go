@@ -0,0 +1,90 @@
+// Command byto-cli manages the byto download queue from a terminal, without
+// the desktop app. It reads and writes the same queue journal, settings and
+// media defaults as the app, so the two shouldn't run at the same time.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+const usage = `byto-cli manages the byto download queue without the desktop app.
+
+Usage:
+  byto-cli [-v] <command> [flags] [arguments]
+
+Commands:
+  add [-quality 1080p] [-path DIR] [-audio] [-items 1,3-5] [-priority high] [-profile NAME] [-rate 2M] [-no-probe] URL...
+        add URLs to the queue using the saved media defaults; -profile signs
+        in with a saved authentication profile
+  list [-json]
+        show the queue
+  status [-json] ID
+        show one item with its playlist entries and latest logs
+  start [-json] [-all] [-rate 2M] [-now] [ID...]
+        download the given items, or every pending, scheduled and failed item,
+        and wait until they finish; -all includes paused items and -rate
+        overrides the global rate limit. Items whose start time hasn't come
+        are skipped, and nothing starts outside the download window, unless
+        -now is given. Ctrl+C pauses them.
+  pause ID...
+        hold queued items back from the next start
+  move ID top|bottom|POSITION
+        move an item within the queue; POSITION starts at 1
+  priority low|normal|high ID...
+        change the priority of items; higher priorities download first
+  remove ID...
+        remove items from the queue
+
+IDs can be shortened to any unique prefix.
+`
+
+func main() {
+	verbose := flag.Bool("v", false, "print the internal log to stderr")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "add":
+		err = runAdd(args)
+	case "list":
+		err = runList(args)
+	case "status":
+		err = runStatus(args)
+	case "start":
+		err = runStart(args)
+	case "pause":
+		err = runPause(args)
+	case "move":
+		err = runMove(args)
+	case "priority":
+		err = runPriority(args)
+	case "remove":
+		err = runRemove(args)
+	case "help":
+		flag.Usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"byto/internal/domain"
+	"byto/internal/queue"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// printer writes queue items and download events either as readable text or
+// as JSON lines. JSON events use the same names and payloads as the events
+// the desktop app sends to its frontend.
+type printer struct {
+	w    io.Writer
+	json bool
+	mu   sync.Mutex
+	// last printed percentage per item, to print progress only when it moves
+	lastPercent map[string]int
+	// finished holds the items whose outcome was printed, so a late status
+	// callback doesn't print after it
+	finished map[string]bool
+}
+
+func newPrinter(w io.Writer, jsonOut bool) *printer {
+	return &printer{
+		w:           w,
+		json:        jsonOut,
+		lastPercent: make(map[string]int),
+		finished:    make(map[string]bool),
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func (p *printer) writeJSON(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	p.w.Write(append(data, '\n'))
+}
+
+func (p *printer) event(name string, payload map[string]interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payload["event"] = name
+	p.writeJSON(payload)
+}
+
+func (p *printer) textf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+func (p *printer) added(media *domain.Media) {
+	if p.json {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.writeJSON(media)
+		return
+	}
+	p.textf("%s\t%s", media.ID, media.Title)
+}
+
+func (p *printer) list(items []*domain.Media) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.json {
+		for _, media := range items {
+			p.writeJSON(media)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tPRIORITY\tPROGRESS\tTITLE\tURL")
+	for _, media := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d%%\t%s\t%s\n", shortID(media.ID), media.Status, media.Priority, media.Progress.Percentage, media.Title, media.URL)
+	}
+	tw.Flush()
+}
+
+// statusLogLines is how many of the latest log lines status shows
+const statusLogLines = 10
+
+func (p *printer) status(media *domain.Media) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.json {
+		p.writeJSON(media)
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", media.ID)
+	fmt.Fprintf(tw, "Title:\t%s\n", media.Title)
+	fmt.Fprintf(tw, "URL:\t%s\n", media.URL)
+	fmt.Fprintf(tw, "Folder:\t%s\n", media.FilePath)
+	fmt.Fprintf(tw, "Status:\t%s\n", media.Status)
+	fmt.Fprintf(tw, "Priority:\t%s\n", media.Priority)
+	fmt.Fprintf(tw, "Progress:\t%d%% (%s of %s)\n", media.Progress.Percentage, formatBytes(media.Progress.DownloadedBytes), formatBytes(media.TotalBytes))
+	if media.StartAfter != nil {
+		fmt.Fprintf(tw, "Start after:\t%s\n", media.StartAfter.Format(time.RFC1123))
+	}
+	if media.RateLimit > 0 {
+		fmt.Fprintf(tw, "Rate limit:\t%s\n", domain.FormatRate(media.RateLimit))
+	}
+	if media.Progress.Skipped > 0 {
+		fmt.Fprintf(tw, "Skipped:\t%d already in the download archive\n", media.Progress.Skipped)
+	}
+	if media.ErrorKind != domain.ErrorNone {
+		fmt.Fprintf(tw, "Error:\t%s - %s\n", media.ErrorKind, media.ErrorHint)
+	}
+	if media.NextRetryAt != nil {
+		fmt.Fprintf(tw, "Next retry:\t%s (attempt %d)\n", media.NextRetryAt.Format(time.RFC1123), media.Attempts+1)
+	}
+	for i, file := range media.OutputFiles {
+		label := ""
+		if i == 0 {
+			label = "Files:"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", label, file)
+	}
+	tw.Flush()
+
+	if len(media.Entries) > 0 {
+		fmt.Fprintln(p.w, "\nEntries:")
+		tw = tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		for _, e := range media.Entries {
+			fmt.Fprintf(tw, "  %d\t%s\t%d%%\t%s\n", e.Index, e.Status, e.Percentage, e.Title)
+		}
+		tw.Flush()
+	}
+
+	logs := media.Progress.Logs
+	if len(logs) > statusLogLines {
+		logs = logs[len(logs)-statusLogLines:]
+	}
+	if len(logs) > 0 {
+		fmt.Fprintln(p.w, "\nLatest logs:")
+		for _, l := range logs {
+			fmt.Fprintln(p.w, "  "+l)
+		}
+	}
+}
+
+// attach wires the media's callbacks to the printer and writes status and
+// title changes back to the queue journal
+func (p *printer) attach(q *queue.Queue, media *domain.Media) {
+	media.OnProgress = func(id string, progress domain.DownloadProgress) {
+		p.mu.Lock()
+		last, seen := p.lastPercent[id]
+		if seen && last == progress.Percentage {
+			p.mu.Unlock()
+			return
+		}
+		p.lastPercent[id] = progress.Percentage
+		p.mu.Unlock()
+
+		if p.json {
+			// Logs are left out, the desktop app shows them separately
+			progress.Logs = nil
+			p.event("download_progress", map[string]interface{}{
+				"id":          id,
+				"title":       media.Title,
+				"total_bytes": media.TotalBytes,
+				"progress":    progress,
+			})
+			return
+		}
+		line := fmt.Sprintf("%s %3d%%", shortID(id), progress.Percentage)
+		if progress.Speed > 0 {
+			line += " " + formatBytes(int64(progress.Speed)) + "/s"
+		}
+		if progress.ETA > 0 {
+			line += " ETA " + (time.Duration(progress.ETA) * time.Second).String()
+		}
+		p.textf("%s  %s", line, media.Title)
+	}
+
+	media.OnStatusChange = func(id string, status domain.DownloadStatus) {
+		q.Persist()
+		// Outcomes are printed by outcome once the attempt returns, as
+		// callbacks may still be running when the command exits
+		if !status.IsRunning() {
+			return
+		}
+		p.mu.Lock()
+		finished := p.finished[id]
+		p.mu.Unlock()
+		if !finished {
+			p.printStatus(media, status)
+		}
+	}
+
+	media.OnTitleChange = func(id string, title string) {
+		q.Persist()
+		if p.json {
+			p.event("download_title", map[string]interface{}{
+				"id":    id,
+				"title": title,
+			})
+		}
+	}
+
+	media.OnEntryChange = func(id string, entry domain.PlaylistEntry) {
+		if p.json {
+			p.event("download_entry", map[string]interface{}{
+				"id":    id,
+				"entry": entry,
+			})
+			return
+		}
+		if entry.IsDone() {
+			p.textf("%s   #%d %s: %s", shortID(id), entry.Index, entry.Status, entry.Title)
+		}
+	}
+
+	media.OnRetry = func(id string, attempts int, at time.Time) {
+		if p.json {
+			p.event("download_retry", map[string]interface{}{
+				"id":            id,
+				"attempts":      attempts,
+				"next_retry_at": at,
+			})
+			return
+		}
+		p.textf("%s retrying at %s", shortID(id), at.Format(time.Kitchen))
+	}
+}
+
+// printStatus prints a status change of the item
+func (p *printer) printStatus(media *domain.Media, status domain.DownloadStatus) {
+	if p.json {
+		payload := map[string]interface{}{
+			"id":     media.ID,
+			"status": status,
+		}
+		if status == domain.Failed {
+			payload["error_kind"] = media.ErrorKind
+			payload["error_hint"] = media.ErrorHint
+		}
+		p.event("download_status", payload)
+		return
+	}
+	if status == domain.Failed && media.ErrorHint != "" {
+		p.textf("%s %s: %s (%s)", shortID(media.ID), status, media.Title, media.ErrorHint)
+		return
+	}
+	p.textf("%s %s: %s", shortID(media.ID), status, media.Title)
+}
+
+// started forgets the outcome of an earlier attempt of the item
+func (p *printer) started(media *domain.Media) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.finished, media.ID)
+}
+
+// outcome prints how an attempt of the item ended
+func (p *printer) outcome(media *domain.Media) {
+	p.mu.Lock()
+	p.finished[media.ID] = true
+	p.mu.Unlock()
+	p.printStatus(media, media.CurrentStatus())
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
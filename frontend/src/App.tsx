@@ -11,16 +11,21 @@ import { AddMediaDialog } from './components/AddMediaDialog';
 import { YtDlpUpdateNotification } from './components/YtDlpUpdateNotification';
 import { GetQueue, RemoveFromQueue, StartDownloads, PauseDownloads, StartSingleDownload, PauseSingleDownload, GetSettings, UpdateSettings, SaveSettings, ShowInFolder, CheckYtDlpUpdate } from '../wailsjs/go/main/App';
 import { EventsOn, EventsOff } from '../wailsjs/runtime/runtime';
-import { domain } from '../wailsjs/go/models';
+import { domain, history, time } from '../wailsjs/go/models';
 import bytoLogo from 'figma:asset/e1c6c4d1df3cefc4435d7cc603c42e22f058f10f.png';
 
+type DownloadStatus = 'pending' | 'downloading' | 'paused' | 'completed' | 'error' | 'processing' | 'skipped' | 'scheduled';
+
 // Map backend status (number) to frontend status (string)
-const statusMap: Record<number, 'pending' | 'downloading' | 'paused' | 'completed' | 'error'> = {
+const statusMap: Record<number, DownloadStatus> = {
     0: 'pending',     // Pending
     1: 'downloading', // InProgress
     2: 'completed',   // Completed
     3: 'error',       // Failed
     4: 'paused',      // Paused
+    5: 'processing',  // Processing
+    6: 'skipped',     // Skipped
+    7: 'scheduled',   // Scheduled
 };
 
 // Map frontend quality string to backend
@@ -51,8 +56,11 @@ interface DownloadVideo {
     filePath: string;
     progress: number;
     fileSize: string;
-    status: 'pending' | 'downloading' | 'paused' | 'completed' | 'error';
+    status: DownloadStatus;
     logs: string[];
+    // note explains the status, e.g. the playlist entry being downloaded or
+    // when a failed item is retried
+    note: string;
 }
 
 // Helper to format bytes
@@ -64,6 +72,30 @@ function formatBytes(bytes: number): string {
     return parseFloat((bytes / Math.pow(k, i)).toFixed(2)) + ' ' + sizes[i];
 }
 
+// Helper to read a backend time, which arrives as an RFC 3339 string
+function toDate(value: time.Time | string): Date {
+    return new Date(value as unknown as string);
+}
+
+// Helper to describe when a failed item is tried again
+function retryNote(attempts: number, at: time.Time | string): string {
+    return `Attempt ${attempts + 1} at ${toDate(at).toLocaleTimeString()}`;
+}
+
+// Helper to describe why an item has its status
+function mediaNote(media: domain.Media): string {
+    if (media.status === 3 && media.next_retry_at) {
+        return retryNote(media.attempts || 0, media.next_retry_at);
+    }
+    if (media.status === 3 && media.error_hint) {
+        return media.error_hint;
+    }
+    if (media.status === 7 && media.start_after) {
+        return `Starts at ${toDate(media.start_after).toLocaleString()}`;
+    }
+    return '';
+}
+
 // Convert backend Media to frontend DownloadVideo
 function mediaToDownloadVideo(media: domain.Media): DownloadVideo {
     const downloaded = media.progress?.downloaded_bytes || 0;
@@ -81,6 +113,7 @@ function mediaToDownloadVideo(media: domain.Media): DownloadVideo {
         fileSize,
         status: statusMap[media.status] || 'pending',
         logs: media.progress?.logs || [],
+        note: mediaNote(media),
     };
 }
 
@@ -152,9 +185,12 @@ export default function App() {
         const unsubStatus = EventsOn('download_status', (data: { id: string; status: number }) => {
             setDownloads(prev => prev.map(d => {
                 if (d.id === data.id) {
+                    const status = statusMap[data.status] || 'pending';
+                    // Retry and failure notes may arrive before the status
                     return {
                         ...d,
-                        status: statusMap[data.status] || 'pending',
+                        status,
+                        note: status === 'error' ? d.note : '',
                     };
                 }
                 return d;
@@ -173,10 +209,83 @@ export default function App() {
             }));
         });
 
+        const unsubMetadata = EventsOn('download_metadata', (data: { id: string; metadata: { title: string; estimated_size: number } }) => {
+            setDownloads(prev => prev.map(d => {
+                if (d.id === data.id) {
+                    const size = data.metadata.estimated_size || 0;
+                    return {
+                        ...d,
+                        fileName: data.metadata.title || d.fileName,
+                        fileSize: d.fileSize === '--' && size > 0 ? `~${formatBytes(size)}` : d.fileSize,
+                    };
+                }
+                return d;
+            }));
+        });
+
+        const unsubEntry = EventsOn('download_entry', (data: { id: string; entry: domain.PlaylistEntry }) => {
+            setDownloads(prev => prev.map(d => {
+                if (d.id === data.id) {
+                    return {
+                        ...d,
+                        note: `Video ${data.entry.index}${data.entry.title ? `: ${data.entry.title}` : ''}`,
+                    };
+                }
+                return d;
+            }));
+        });
+
+        const unsubRetry = EventsOn('download_retry', (data: { id: string; attempts: number; next_retry_at: string }) => {
+            setDownloads(prev => prev.map(d => {
+                if (d.id === data.id) {
+                    return {
+                        ...d,
+                        note: retryNote(data.attempts, data.next_retry_at),
+                    };
+                }
+                return d;
+            }));
+        });
+
+        const unsubHistory = EventsOn('history_added', (entry: history.Entry) => {
+            setDownloads(prev => prev.map(d => {
+                if (d.id === entry.media_id) {
+                    return {
+                        ...d,
+                        fileName: entry.title || d.fileName,
+                        note: entry.error_hint || d.note,
+                    };
+                }
+                return d;
+            }));
+        });
+
+        // Links copied while auto-add is off are offered in the add dialog
+        const unsubClipboardLink = EventsOn('clipboard_link', (data: { url: string }) => {
+            setPendingUrl(data.url);
+            setShowAddMediaDialog(true);
+        });
+
+        // Links added from the clipboard are already queued, so reload the
+        // queue to show them
+        const unsubClipboardAdded = EventsOn('clipboard_added', () => {
+            GetQueue().then(queue => {
+                setDownloads((queue || []).map(mediaToDownloadVideo));
+            }).catch(error => {
+                console.error('Error loading queue:', error);
+            });
+        });
+
         return () => {
             EventsOff('download_progress');
             EventsOff('download_status');
             EventsOff('download_title');
+            EventsOff('download_metadata');
+            EventsOff('download_entry');
+            EventsOff('download_retry');
+            EventsOff('history_added');
+            EventsOff('clipboard_link');
+            EventsOff('clipboard_added');
         };
     }, []);
 
@@ -195,7 +304,8 @@ export default function App() {
             progress: 0,
             fileSize: '--',
             status: 'pending',
-            logs: []
+            logs: [],
+            note: ''
         };
         setDownloads([...downloads, newDownload]);
         setUrlInput('');
@@ -260,7 +370,7 @@ export default function App() {
         }
     };
 
-    const activeDownloads = downloads.filter(d => d.status === 'downloading').length;
+    const activeDownloads = downloads.filter(d => d.status === 'downloading' || d.status === 'processing').length;
 
     return (
         <div className="min-h-screen bg-[#0a0a0a] flex flex-col">
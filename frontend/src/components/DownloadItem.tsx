@@ -1,5 +1,5 @@
 import { useState } from 'react';
-import { Play, Pause, Square, Trash2, ChevronDown, ChevronUp, CheckCircle, Loader2, AlertCircle, FolderOpen } from 'lucide-react';
+import { Play, Pause, Square, Trash2, ChevronDown, ChevronUp, CheckCircle, Loader2, AlertCircle, FolderOpen, Clock, SkipForward } from 'lucide-react';
 import { Button } from './ui/button';
 import { Progress } from './ui/progress';
 
@@ -9,10 +9,22 @@ interface DownloadVideo {
   fileName: string;
   progress: number;
   fileSize: string;
-  status: 'pending' | 'downloading' | 'paused' | 'completed' | 'error';
+  status: 'pending' | 'downloading' | 'paused' | 'completed' | 'error' | 'processing' | 'skipped' | 'scheduled';
   logs: string[];
+  note: string;
 }
 
+const statusLabels: Record<DownloadVideo['status'], string> = {
+  pending: 'Pending',
+  downloading: 'Downloading...',
+  processing: 'Processing...',
+  paused: 'Paused',
+  completed: 'Completed',
+  error: 'Failed',
+  skipped: 'Already downloaded',
+  scheduled: 'Scheduled',
+};
+
 interface DownloadItemProps {
   download: DownloadVideo;
   onAction: (id: string, action: 'start' | 'pause' | 'resume' | 'delete') => void;
@@ -27,8 +39,14 @@ export function DownloadItem({ download, onAction, onRemove, onShowInFolder }: D
     switch (download.status) {
       case 'downloading':
         return <Loader2 className="size-4 text-blue-500 animate-spin" />;
+      case 'processing':
+        return <Loader2 className="size-4 text-purple-500 animate-spin" />;
       case 'completed':
         return <CheckCircle className="size-4 text-green-500" />;
+      case 'skipped':
+        return <SkipForward className="size-4 text-gray-400" />;
+      case 'scheduled':
+        return <Clock className="size-4 text-yellow-500" />;
       case 'error':
         return <AlertCircle className="size-4 text-red-500" />;
       case 'paused':
@@ -42,8 +60,12 @@ export function DownloadItem({ download, onAction, onRemove, onShowInFolder }: D
     switch (download.status) {
       case 'downloading':
         return 'bg-blue-500';
+      case 'processing':
+        return 'bg-purple-500';
       case 'completed':
         return 'bg-green-500';
+      case 'scheduled':
+        return 'bg-yellow-500';
       case 'error':
         return 'bg-red-500';
       case 'paused':
@@ -69,7 +91,10 @@ export function DownloadItem({ download, onAction, onRemove, onShowInFolder }: D
             {/* Progress Bar */}
             <div className="space-y-1">
               <div className="flex justify-between text-sm">
-                <span className="text-gray-400">{download.status === 'completed' ? 'Completed' : download.status === 'downloading' ? 'Downloading...' : download.status === 'paused' ? 'Paused' : 'Pending'}</span>
+                <span className="text-gray-400 truncate">
+                  {statusLabels[download.status]}
+                  {download.note && <span className="text-gray-500"> • {download.note}</span>}
+                </span>
                 <div className="flex items-center gap-3">
                   <span className="text-gray-400">{download.fileSize}</span>
                   <span className="text-xs text-gray-300">{download.progress}%</span>
@@ -94,7 +119,7 @@ export function DownloadItem({ download, onAction, onRemove, onShowInFolder }: D
 
           {/* Action Buttons */}
           <div className="flex gap-1">
-            {download.status === 'pending' || download.status === 'paused' ? (
+            {download.status === 'pending' || download.status === 'paused' || download.status === 'error' || download.status === 'scheduled' ? (
               <Button
                 variant="outline"
                 size="icon"
@@ -104,7 +129,7 @@ export function DownloadItem({ download, onAction, onRemove, onShowInFolder }: D
               >
                 <Play className="size-4" />
               </Button>
-            ) : download.status === 'downloading' ? (
+            ) : download.status === 'downloading' || download.status === 'processing' ? (
               <Button
                 variant="outline"
                 size="icon"
@@ -2,6 +2,9 @@
 // This file is automatically generated. DO NOT EDIT
 import {domain} from '../models';
 import {updater} from '../models';
+import {history} from '../models';
+import {archive} from '../models';
+import {time} from '../models';
 
 export function AddToQueue(arg1:string,arg2:string,arg3:string,arg4:boolean,arg5:boolean,arg6:domain.PlaylistSelection):Promise<string>;
 
@@ -13,16 +16,36 @@ export function CheckYtDlp():Promise<updater.YtDlpStatus>;
 
 export function CheckYtDlpUpdate():Promise<updater.UpdateResult>;
 
+export function ClearArchive():Promise<void>;
+
+export function ClearHistory():Promise<void>;
+
+export function DeleteAuthProfile(arg1:string):Promise<void>;
+
+export function DeleteHistoryEntry(arg1:string):Promise<void>;
+
 export function DownloadAppUpdate(arg1:string):Promise<string>;
 
 export function DownloadFfmpeg():Promise<void>;
 
 export function DownloadYtDlp():Promise<void>;
 
+export function ExportHistory(arg1:history.Filter,arg2:string):Promise<string>;
+
 export function GetAppVersion():Promise<string>;
 
+export function GetArchiveEntries():Promise<Array<archive.Entry>>;
+
+export function GetAuthProfiles():Promise<Array<domain.AuthProfile>>;
+
+export function GetAvailableFormats(arg1:string):Promise<Array<domain.FormatInfo>>;
+
+export function GetCookieBrowsers():Promise<Array<string>>;
+
 export function GetDefaultDownloadPath():Promise<string>;
 
+export function GetHistory():Promise<Array<history.Entry>>;
+
 export function GetMediaDefaults():Promise<domain.MediaDefaults>;
 
 export function GetQueue():Promise<Array<domain.Media>>;
@@ -35,22 +58,64 @@ export function Greet(arg1:string):Promise<string>;
 
 export function LaunchInstaller(arg1:string):Promise<void>;
 
+export function MoveInQueue(arg1:string,arg2:number):Promise<void>;
+
+export function MoveToBottom(arg1:string):Promise<void>;
+
+export function MoveToTop(arg1:string):Promise<void>;
+
+export function OpenMediaFile(arg1:string,arg2:number):Promise<void>;
+
 export function PauseDownloads():Promise<void>;
 
 export function PauseSingleDownload(arg1:string):Promise<void>;
 
 export function PerformFullUpdate():Promise<Record<string, any>>;
 
+export function PreviewOutputTemplate(arg1:string,arg2:string):Promise<string>;
+
+export function RemoveArchiveEntries(arg1:Array<archive.Entry>):Promise<number>;
+
 export function RemoveFromQueue(arg1:string):Promise<void>;
 
+export function RequeueFromHistory(arg1:string):Promise<string>;
+
+export function RevealMedia(arg1:string):Promise<void>;
+
+export function SaveAuthProfile(arg1:domain.AuthProfile):Promise<domain.AuthProfile>;
+
 export function SaveMediaDefaults():Promise<void>;
 
 export function SaveSettings():Promise<void>;
 
+export function SearchArchive(arg1:string):Promise<Array<archive.Entry>>;
+
+export function SearchHistory(arg1:history.Filter):Promise<Array<history.Entry>>;
+
+export function SelectCookieFile():Promise<string>;
+
 export function SelectDownloadFolder():Promise<string>;
 
 export function SelectDownloadFolderWithDefault(arg1:string):Promise<string>;
 
+export function SetAudioProfile(arg1:string,arg2:domain.AudioProfile):Promise<void>;
+
+export function SetAuthProfile(arg1:string,arg2:string):Promise<void>;
+
+export function SetEmbedOptions(arg1:string,arg2:domain.EmbedOptions):Promise<void>;
+
+export function SetFormatSelection(arg1:string,arg2:domain.FormatSelection):Promise<void>;
+
+export function SetOutputTemplate(arg1:string,arg2:string):Promise<void>;
+
+export function SetPriority(arg1:string,arg2:domain.Priority):Promise<void>;
+
+export function SetRateLimit(arg1:string,arg2:number):Promise<void>;
+
+export function SetStartAfter(arg1:string,arg2:time.Time):Promise<void>;
+
+export function SetSubtitleOptions(arg1:string,arg2:domain.SubtitleOptions):Promise<void>;
+
 export function ShowInFolder(arg1:string):Promise<void>;
 
 export function ShutDown():Promise<void>;
@@ -59,8 +124,34 @@ export function StartDownloads():Promise<void>;
 
 export function StartSingleDownload(arg1:string):Promise<void>;
 
+export function TestConnection(arg1:domain.NetworkSettings):Promise<updater.ConnectionResult>;
+
+export function UpdateAPISettings(arg1:boolean,arg2:number,arg3:string):Promise<domain.APISettings>;
+
+export function UpdateAudioDefaults(arg1:domain.AudioProfile):Promise<void>;
+
+export function UpdateClipboardSettings(arg1:domain.ClipboardSettings):Promise<void>;
+
+export function UpdateDownloadArchiveSetting(arg1:boolean):Promise<void>;
+
+export function UpdateDownloadWindow(arg1:domain.DownloadWindow):Promise<void>;
+
+export function UpdateEmbedDefaults(arg1:domain.EmbedOptions):Promise<void>;
+
 export function UpdateMediaDefaults(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
+export function UpdateNetworkSettings(arg1:domain.NetworkSettings):Promise<void>;
+
+export function UpdateOutputTemplateDefault(arg1:string):Promise<void>;
+
+export function UpdateRateLimit(arg1:number):Promise<void>;
+
+export function UpdateRetryPolicy(arg1:domain.RetryPolicy):Promise<void>;
+
 export function UpdateSettings(arg1:number):Promise<void>;
 
+export function UpdateSiteLimits(arg1:domain.SiteLimits):Promise<void>;
+
+export function UpdateSubtitleDefaults(arg1:domain.SubtitleOptions):Promise<void>;
+
 export function UpdateYTDLP():Promise<updater.UpdateResult>;
@@ -22,6 +22,22 @@ export function CheckYtDlpUpdate() {
   return window['go']['main']['App']['CheckYtDlpUpdate']();
 }
 
+export function ClearArchive() {
+  return window['go']['main']['App']['ClearArchive']();
+}
+
+export function ClearHistory() {
+  return window['go']['main']['App']['ClearHistory']();
+}
+
+export function DeleteAuthProfile(arg1) {
+  return window['go']['main']['App']['DeleteAuthProfile'](arg1);
+}
+
+export function DeleteHistoryEntry(arg1) {
+  return window['go']['main']['App']['DeleteHistoryEntry'](arg1);
+}
+
 export function DownloadAppUpdate(arg1) {
   return window['go']['main']['App']['DownloadAppUpdate'](arg1);
 }
@@ -34,14 +50,38 @@ export function DownloadYtDlp() {
   return window['go']['main']['App']['DownloadYtDlp']();
 }
 
+export function ExportHistory(arg1, arg2) {
+  return window['go']['main']['App']['ExportHistory'](arg1, arg2);
+}
+
 export function GetAppVersion() {
   return window['go']['main']['App']['GetAppVersion']();
 }
 
+export function GetArchiveEntries() {
+  return window['go']['main']['App']['GetArchiveEntries']();
+}
+
+export function GetAuthProfiles() {
+  return window['go']['main']['App']['GetAuthProfiles']();
+}
+
+export function GetAvailableFormats(arg1) {
+  return window['go']['main']['App']['GetAvailableFormats'](arg1);
+}
+
+export function GetCookieBrowsers() {
+  return window['go']['main']['App']['GetCookieBrowsers']();
+}
+
 export function GetDefaultDownloadPath() {
   return window['go']['main']['App']['GetDefaultDownloadPath']();
 }
 
+export function GetHistory() {
+  return window['go']['main']['App']['GetHistory']();
+}
+
 export function GetMediaDefaults() {
   return window['go']['main']['App']['GetMediaDefaults']();
 }
@@ -66,6 +106,22 @@ export function LaunchInstaller(arg1) {
   return window['go']['main']['App']['LaunchInstaller'](arg1);
 }
 
+export function MoveInQueue(arg1, arg2) {
+  return window['go']['main']['App']['MoveInQueue'](arg1, arg2);
+}
+
+export function MoveToBottom(arg1) {
+  return window['go']['main']['App']['MoveToBottom'](arg1);
+}
+
+export function MoveToTop(arg1) {
+  return window['go']['main']['App']['MoveToTop'](arg1);
+}
+
+export function OpenMediaFile(arg1, arg2) {
+  return window['go']['main']['App']['OpenMediaFile'](arg1, arg2);
+}
+
 export function PauseDownloads() {
   return window['go']['main']['App']['PauseDownloads']();
 }
@@ -78,10 +134,30 @@ export function PerformFullUpdate() {
   return window['go']['main']['App']['PerformFullUpdate']();
 }
 
+export function PreviewOutputTemplate(arg1, arg2) {
+  return window['go']['main']['App']['PreviewOutputTemplate'](arg1, arg2);
+}
+
+export function RemoveArchiveEntries(arg1) {
+  return window['go']['main']['App']['RemoveArchiveEntries'](arg1);
+}
+
 export function RemoveFromQueue(arg1) {
   return window['go']['main']['App']['RemoveFromQueue'](arg1);
 }
 
+export function RequeueFromHistory(arg1) {
+  return window['go']['main']['App']['RequeueFromHistory'](arg1);
+}
+
+export function RevealMedia(arg1) {
+  return window['go']['main']['App']['RevealMedia'](arg1);
+}
+
+export function SaveAuthProfile(arg1) {
+  return window['go']['main']['App']['SaveAuthProfile'](arg1);
+}
+
 export function SaveMediaDefaults() {
   return window['go']['main']['App']['SaveMediaDefaults']();
 }
@@ -90,6 +166,18 @@ export function SaveSettings() {
   return window['go']['main']['App']['SaveSettings']();
 }
 
+export function SearchArchive(arg1) {
+  return window['go']['main']['App']['SearchArchive'](arg1);
+}
+
+export function SearchHistory(arg1) {
+  return window['go']['main']['App']['SearchHistory'](arg1);
+}
+
+export function SelectCookieFile() {
+  return window['go']['main']['App']['SelectCookieFile']();
+}
+
 export function SelectDownloadFolder() {
   return window['go']['main']['App']['SelectDownloadFolder']();
 }
@@ -98,6 +186,42 @@ export function SelectDownloadFolderWithDefault(arg1) {
   return window['go']['main']['App']['SelectDownloadFolderWithDefault'](arg1);
 }
 
+export function SetAudioProfile(arg1, arg2) {
+  return window['go']['main']['App']['SetAudioProfile'](arg1, arg2);
+}
+
+export function SetAuthProfile(arg1, arg2) {
+  return window['go']['main']['App']['SetAuthProfile'](arg1, arg2);
+}
+
+export function SetEmbedOptions(arg1, arg2) {
+  return window['go']['main']['App']['SetEmbedOptions'](arg1, arg2);
+}
+
+export function SetFormatSelection(arg1, arg2) {
+  return window['go']['main']['App']['SetFormatSelection'](arg1, arg2);
+}
+
+export function SetOutputTemplate(arg1, arg2) {
+  return window['go']['main']['App']['SetOutputTemplate'](arg1, arg2);
+}
+
+export function SetPriority(arg1, arg2) {
+  return window['go']['main']['App']['SetPriority'](arg1, arg2);
+}
+
+export function SetRateLimit(arg1, arg2) {
+  return window['go']['main']['App']['SetRateLimit'](arg1, arg2);
+}
+
+export function SetStartAfter(arg1, arg2) {
+  return window['go']['main']['App']['SetStartAfter'](arg1, arg2);
+}
+
+export function SetSubtitleOptions(arg1, arg2) {
+  return window['go']['main']['App']['SetSubtitleOptions'](arg1, arg2);
+}
+
 export function ShowInFolder(arg1) {
   return window['go']['main']['App']['ShowInFolder'](arg1);
 }
@@ -114,14 +238,66 @@ export function StartSingleDownload(arg1) {
   return window['go']['main']['App']['StartSingleDownload'](arg1);
 }
 
+export function TestConnection(arg1) {
+  return window['go']['main']['App']['TestConnection'](arg1);
+}
+
+export function UpdateAPISettings(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateAPISettings'](arg1, arg2, arg3);
+}
+
+export function UpdateAudioDefaults(arg1) {
+  return window['go']['main']['App']['UpdateAudioDefaults'](arg1);
+}
+
+export function UpdateClipboardSettings(arg1) {
+  return window['go']['main']['App']['UpdateClipboardSettings'](arg1);
+}
+
+export function UpdateDownloadArchiveSetting(arg1) {
+  return window['go']['main']['App']['UpdateDownloadArchiveSetting'](arg1);
+}
+
+export function UpdateDownloadWindow(arg1) {
+  return window['go']['main']['App']['UpdateDownloadWindow'](arg1);
+}
+
+export function UpdateEmbedDefaults(arg1) {
+  return window['go']['main']['App']['UpdateEmbedDefaults'](arg1);
+}
+
 export function UpdateMediaDefaults(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateMediaDefaults'](arg1, arg2, arg3);
 }
 
+export function UpdateNetworkSettings(arg1) {
+  return window['go']['main']['App']['UpdateNetworkSettings'](arg1);
+}
+
+export function UpdateOutputTemplateDefault(arg1) {
+  return window['go']['main']['App']['UpdateOutputTemplateDefault'](arg1);
+}
+
+export function UpdateRateLimit(arg1) {
+  return window['go']['main']['App']['UpdateRateLimit'](arg1);
+}
+
+export function UpdateRetryPolicy(arg1) {
+  return window['go']['main']['App']['UpdateRetryPolicy'](arg1);
+}
+
 export function UpdateSettings(arg1) {
   return window['go']['main']['App']['UpdateSettings'](arg1);
 }
 
+export function UpdateSiteLimits(arg1) {
+  return window['go']['main']['App']['UpdateSiteLimits'](arg1);
+}
+
+export function UpdateSubtitleDefaults(arg1) {
+  return window['go']['main']['App']['UpdateSubtitleDefaults'](arg1);
+}
+
 export function UpdateYTDLP() {
   return window['go']['main']['App']['UpdateYTDLP']();
 }
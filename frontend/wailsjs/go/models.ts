@@ -1,9 +1,100 @@
+export namespace archive {
+	
+	export class Entry {
+	    extractor: string;
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.extractor = source["extractor"];
+	        this.id = source["id"];
+	    }
+	}
+
+}
+
 export namespace domain {
 	
+	export class APISettings {
+	    enabled: boolean;
+	    port: number;
+	    token: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new APISettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.port = source["port"];
+	        this.token = source["token"];
+	    }
+	}
+	export class AudioProfile {
+	    codec: string;
+	    bitrate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.codec = source["codec"];
+	        this.bitrate = source["bitrate"];
+	    }
+	}
+	export class AuthProfile {
+	    id: string;
+	    name: string;
+	    sites: string[];
+	    cookie_file?: string;
+	    browser?: string;
+	    browser_profile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuthProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.sites = source["sites"];
+	        this.cookie_file = source["cookie_file"];
+	        this.browser = source["browser"];
+	        this.browser_profile = source["browser_profile"];
+	    }
+	}
+	export class ClipboardSettings {
+	    enabled: boolean;
+	    auto_add: boolean;
+	    sites?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ClipboardSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.auto_add = source["auto_add"];
+	        this.sites = source["sites"];
+	    }
+	}
 	export class DownloadProgress {
 	    percentage: number;
 	    downloaded_bytes: number;
 	    logs: string[];
+	    speed: number;
+	    eta: number;
+	    elapsed: number;
+	    skipped: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new DownloadProgress(source);
@@ -14,6 +105,144 @@ export namespace domain {
 	        this.percentage = source["percentage"];
 	        this.downloaded_bytes = source["downloaded_bytes"];
 	        this.logs = source["logs"];
+	        this.speed = source["speed"];
+	        this.eta = source["eta"];
+	        this.elapsed = source["elapsed"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class DownloadWindow {
+	    enabled: boolean;
+	    start: string;
+	    end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DownloadWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class EmbedOptions {
+	    thumbnail: boolean;
+	    metadata: boolean;
+	    chapters: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmbedOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.thumbnail = source["thumbnail"];
+	        this.metadata = source["metadata"];
+	        this.chapters = source["chapters"];
+	    }
+	}
+	export class FormatInfo {
+	    format_id: string;
+	    ext: string;
+	    resolution: string;
+	    width: number;
+	    height: number;
+	    fps: number;
+	    vcodec: string;
+	    acodec: string;
+	    dynamic_range: string;
+	    filesize: number;
+	    bitrate: number;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormatInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format_id = source["format_id"];
+	        this.ext = source["ext"];
+	        this.resolution = source["resolution"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.fps = source["fps"];
+	        this.vcodec = source["vcodec"];
+	        this.acodec = source["acodec"];
+	        this.dynamic_range = source["dynamic_range"];
+	        this.filesize = source["filesize"];
+	        this.bitrate = source["bitrate"];
+	        this.note = source["note"];
+	    }
+	}
+	export class FormatSelection {
+	    format_id?: string;
+	    codec?: string;
+	    container?: string;
+	    max_height?: number;
+	    fps?: number;
+	    hdr?: boolean;
+	    vcodec?: string;
+	    acodec?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormatSelection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format_id = source["format_id"];
+	        this.codec = source["codec"];
+	        this.container = source["container"];
+	        this.max_height = source["max_height"];
+	        this.fps = source["fps"];
+	        this.hdr = source["hdr"];
+	        this.vcodec = source["vcodec"];
+	        this.acodec = source["acodec"];
+	    }
+	}
+	export class PlaylistEntry {
+	    index: number;
+	    title: string;
+	    status: number;
+	    percentage: number;
+	    downloaded_bytes: number;
+	    total_bytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlaylistEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.title = source["title"];
+	        this.status = source["status"];
+	        this.percentage = source["percentage"];
+	        this.downloaded_bytes = source["downloaded_bytes"];
+	        this.total_bytes = source["total_bytes"];
+	    }
+	}
+	export class SubtitleOptions {
+	    enabled: boolean;
+	    languages: string[];
+	    source: string;
+	    format: string;
+	    embed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubtitleOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.languages = source["languages"];
+	        this.source = source["source"];
+	        this.format = source["format"];
+	        this.embed = source["embed"];
 	    }
 	}
 	export class PlaylistSelection {
@@ -46,6 +275,28 @@ export namespace domain {
 	    progress: DownloadProgress;
 	    is_playlist: boolean;
 	    playlist_selection?: PlaylistSelection;
+	    format?: FormatSelection;
+	    subtitles: SubtitleOptions;
+	    embed: EmbedOptions;
+	    audio: AudioProfile;
+	    output_template?: string;
+	    auth_profile_id?: string;
+	    rate_limit?: number;
+	    priority: number;
+	    start_after?: time.Time;
+	    added_at: time.Time;
+	    started_at?: time.Time;
+	    uploader: string;
+	    duration: number;
+	    thumbnail_url: string;
+	    estimated_size: number;
+	    extractor: string;
+	    output_files?: string[];
+	    entries?: PlaylistEntry[];
+	    attempts: number;
+	    next_retry_at?: time.Time;
+	    error_kind?: string;
+	    error_hint?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Media(source);
@@ -64,6 +315,28 @@ export namespace domain {
 	        this.progress = this.convertValues(source["progress"], DownloadProgress);
 	        this.is_playlist = source["is_playlist"];
 	        this.playlist_selection = this.convertValues(source["playlist_selection"], PlaylistSelection);
+	        this.format = this.convertValues(source["format"], FormatSelection);
+	        this.subtitles = this.convertValues(source["subtitles"], SubtitleOptions);
+	        this.embed = this.convertValues(source["embed"], EmbedOptions);
+	        this.audio = this.convertValues(source["audio"], AudioProfile);
+	        this.output_template = source["output_template"];
+	        this.auth_profile_id = source["auth_profile_id"];
+	        this.rate_limit = source["rate_limit"];
+	        this.priority = source["priority"];
+	        this.start_after = this.convertValues(source["start_after"], time.Time);
+	        this.added_at = this.convertValues(source["added_at"], time.Time);
+	        this.started_at = this.convertValues(source["started_at"], time.Time);
+	        this.uploader = source["uploader"];
+	        this.duration = source["duration"];
+	        this.thumbnail_url = source["thumbnail_url"];
+	        this.estimated_size = source["estimated_size"];
+	        this.extractor = source["extractor"];
+	        this.output_files = source["output_files"];
+	        this.entries = this.convertValues(source["entries"], PlaylistEntry);
+	        this.attempts = source["attempts"];
+	        this.next_retry_at = this.convertValues(source["next_retry_at"], time.Time);
+	        this.error_kind = source["error_kind"];
+	        this.error_hint = source["error_hint"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -88,6 +361,10 @@ export namespace domain {
 	    quality: number;
 	    download_path: string;
 	    only_audio: boolean;
+	    subtitles: SubtitleOptions;
+	    embed: EmbedOptions;
+	    audio: AudioProfile;
+	    output_template: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new MediaDefaults(source);
@@ -98,11 +375,94 @@ export namespace domain {
 	        this.quality = source["quality"];
 	        this.download_path = source["download_path"];
 	        this.only_audio = source["only_audio"];
+	        this.subtitles = this.convertValues(source["subtitles"], SubtitleOptions);
+	        this.embed = this.convertValues(source["embed"], EmbedOptions);
+	        this.audio = this.convertValues(source["audio"], AudioProfile);
+	        this.output_template = source["output_template"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NetworkSettings {
+	    proxy_url: string;
+	    proxy_username?: string;
+	    proxy_password?: string;
+	    source_address: string;
+	    ip_version: string;
+	    socket_timeout_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proxy_url = source["proxy_url"];
+	        this.proxy_username = source["proxy_username"];
+	        this.proxy_password = source["proxy_password"];
+	        this.source_address = source["source_address"];
+	        this.ip_version = source["ip_version"];
+	        this.socket_timeout_seconds = source["socket_timeout_seconds"];
+	    }
+	}
+	
+	
+	export class RetryPolicy {
+	    max_attempts: number;
+	    base_delay_seconds: number;
+	    retry_on: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RetryPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.max_attempts = source["max_attempts"];
+	        this.base_delay_seconds = source["base_delay_seconds"];
+	        this.retry_on = source["retry_on"];
 	    }
 	}
+	export class SiteLimit {
+	    site: string;
+	    max_parallel: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SiteLimit(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.site = source["site"];
+	        this.max_parallel = source["max_parallel"];
+	    }
+	}
 	export class Setting {
 	    parallel_downloads: number;
+	    use_download_archive: boolean;
+	    retry: RetryPolicy;
+	    api: APISettings;
+	    rate_limit: number;
+	    network: NetworkSettings;
+	    download_window: DownloadWindow;
+	    site_limits: SiteLimit[];
+	    clipboard: ClipboardSettings;
 	
 	    static createFrom(source: any = {}) {
 	        return new Setting(source);
@@ -111,6 +471,205 @@ export namespace domain {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.parallel_downloads = source["parallel_downloads"];
+	        this.use_download_archive = source["use_download_archive"];
+	        this.retry = this.convertValues(source["retry"], RetryPolicy);
+	        this.api = this.convertValues(source["api"], APISettings);
+	        this.rate_limit = source["rate_limit"];
+	        this.network = this.convertValues(source["network"], NetworkSettings);
+	        this.download_window = this.convertValues(source["download_window"], DownloadWindow);
+	        this.site_limits = this.convertValues(source["site_limits"], SiteLimit);
+	        this.clipboard = this.convertValues(source["clipboard"], ClipboardSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
+export namespace history {
+	
+	export class Options {
+	    quality: number;
+	    only_audio: boolean;
+	    is_playlist: boolean;
+	    playlist_selection?: domain.PlaylistSelection;
+	    format?: domain.FormatSelection;
+	    subtitles: domain.SubtitleOptions;
+	    embed: domain.EmbedOptions;
+	    audio: domain.AudioProfile;
+	    output_template?: string;
+	    auth_profile_id?: string;
+	    rate_limit?: number;
+	    priority: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.quality = source["quality"];
+	        this.only_audio = source["only_audio"];
+	        this.is_playlist = source["is_playlist"];
+	        this.playlist_selection = this.convertValues(source["playlist_selection"], domain.PlaylistSelection);
+	        this.format = this.convertValues(source["format"], domain.FormatSelection);
+	        this.subtitles = this.convertValues(source["subtitles"], domain.SubtitleOptions);
+	        this.embed = this.convertValues(source["embed"], domain.EmbedOptions);
+	        this.audio = this.convertValues(source["audio"], domain.AudioProfile);
+	        this.output_template = source["output_template"];
+	        this.auth_profile_id = source["auth_profile_id"];
+	        this.rate_limit = source["rate_limit"];
+	        this.priority = source["priority"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Entry {
+	    id: string;
+	    media_id: string;
+	    url: string;
+	    title: string;
+	    folder: string;
+	    files?: string[];
+	    size: number;
+	    duration: number;
+	    uploader: string;
+	    extractor: string;
+	    status: number;
+	    error_kind?: string;
+	    error_hint?: string;
+	    added_at: time.Time;
+	    started_at?: time.Time;
+	    finished_at: time.Time;
+	    options: Options;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.media_id = source["media_id"];
+	        this.url = source["url"];
+	        this.title = source["title"];
+	        this.folder = source["folder"];
+	        this.files = source["files"];
+	        this.size = source["size"];
+	        this.duration = source["duration"];
+	        this.uploader = source["uploader"];
+	        this.extractor = source["extractor"];
+	        this.status = source["status"];
+	        this.error_kind = source["error_kind"];
+	        this.error_hint = source["error_hint"];
+	        this.added_at = this.convertValues(source["added_at"], time.Time);
+	        this.started_at = this.convertValues(source["started_at"], time.Time);
+	        this.finished_at = this.convertValues(source["finished_at"], time.Time);
+	        this.options = this.convertValues(source["options"], Options);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Filter {
+	    query: string;
+	    statuses: number[];
+	    from?: time.Time;
+	    to?: time.Time;
+	
+	    static createFrom(source: any = {}) {
+	        return new Filter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = source["query"];
+	        this.statuses = source["statuses"];
+	        this.from = this.convertValues(source["from"], time.Time);
+	        this.to = this.convertValues(source["to"], time.Time);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace time {
+	
+	export class Time {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new Time(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
 	    }
 	}
 
@@ -118,6 +677,22 @@ export namespace domain {
 
 export namespace updater {
 	
+	export class ConnectionResult {
+	    success: boolean;
+	    message: string;
+	    latency_ms: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.latency_ms = source["latency_ms"];
+	    }
+	}
 	export class FfmpegStatus {
 	    installed: boolean;
 	    path: string;
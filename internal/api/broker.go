@@ -0,0 +1,58 @@
+package api
+
+import "sync"
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before new events are dropped for it
+const subscriberBuffer = 64
+
+// Event is a named payload, the same as the events the app sends to its
+// frontend
+type Event struct {
+	Name    string
+	Payload any
+}
+
+// Broker fans download events out to every connected event stream
+type Broker struct {
+	subscribers map[chan Event]struct{}
+	mu          sync.Mutex
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving every published event until
+// Unsubscribe is called with it
+func (b *Broker) Subscribe() chan Event {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *Broker) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends the event to all subscribers without blocking. Subscribers
+// that are too far behind miss it.
+func (b *Broker) Publish(name string, payload any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- Event{Name: name, Payload: payload}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package api_test
+
+import (
+	"byto/internal/api"
+	"testing"
+)
+
+func TestBroker_PublishReachesSubscribers(t *testing.T) {
+	broker := api.NewBroker()
+	a := broker.Subscribe()
+	b := broker.Subscribe()
+	defer broker.Unsubscribe(a)
+	defer broker.Unsubscribe(b)
+
+	broker.Publish("download_title", map[string]interface{}{"id": "1"})
+
+	for _, ch := range []chan api.Event{a, b} {
+		event := <-ch
+		if event.Name != "download_title" {
+			t.Errorf("expected download_title, got %q", event.Name)
+		}
+	}
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	broker := api.NewBroker()
+	ch := broker.Subscribe()
+	defer broker.Unsubscribe(ch)
+
+	for i := 0; i < 1000; i++ {
+		broker.Publish("download_progress", i)
+	}
+	if len(ch) != cap(ch) {
+		t.Errorf("expected a full buffer, got %d of %d", len(ch), cap(ch))
+	}
+}
+
+func TestBroker_UnsubscribeClosesChannel(t *testing.T) {
+	broker := api.NewBroker()
+	ch := broker.Subscribe()
+	broker.Unsubscribe(ch)
+	broker.Unsubscribe(ch)
+
+	if _, ok := <-ch; ok {
+		t.Error("expected the channel to be closed")
+	}
+	broker.Publish("download_status", nil)
+}
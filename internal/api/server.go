@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"byto/internal/domain"
+	"byto/internal/queue"
+)
+
+// eventsPath is the event stream route, the only one accepting the token as
+// a query parameter
+const eventsPath = "/api/events"
+
+// keepAliveInterval is how often an idle event stream gets a comment line so
+// proxies and browsers don't close it
+const keepAliveInterval = 30 * time.Second
+
+// Controller is the part of the app the API exposes
+type Controller interface {
+	AddToQueue(url string, quality string, customPath string, onlyAudio bool, isPlaylist bool, playlistSelection domain.PlaylistSelection) string
+	GetQueue() []*domain.Media
+	StartDownloads()
+	PauseSingleDownload(id string) error
+	RemoveFromQueue(id string) error
+}
+
+// AddRequest is the body of POST /api/queue. An empty quality uses the
+// default quality from the media defaults.
+type AddRequest struct {
+	URL               string                   `json:"url"`
+	Quality           string                   `json:"quality"`
+	Path              string                   `json:"path"`
+	OnlyAudio         bool                     `json:"only_audio"`
+	IsPlaylist        bool                     `json:"is_playlist"`
+	PlaylistSelection domain.PlaylistSelection `json:"playlist_selection"`
+}
+
+// Server is the local HTTP/JSON control API. It only listens on the
+// loopback interface and every request needs the bearer token.
+type Server struct {
+	controller Controller
+	broker     *Broker
+	token      string
+	httpServer *http.Server
+}
+
+func NewServer(controller Controller, broker *Broker, token string) *Server {
+	return &Server{
+		controller: controller,
+		broker:     broker,
+		token:      token,
+	}
+}
+
+// GenerateToken returns a random token for new API configurations
+func GenerateToken() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("Error generating API token: %v", err)
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Handler returns the API routes wrapped in CORS and token checks
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/queue", s.handleGetQueue)
+	mux.HandleFunc("POST /api/queue", s.handleAdd)
+	mux.HandleFunc("DELETE /api/queue/{id}", s.handleRemove)
+	mux.HandleFunc("POST /api/queue/{id}/pause", s.handlePause)
+	mux.HandleFunc("POST /api/start", s.handleStart)
+	mux.HandleFunc("GET "+eventsPath, s.handleEvents)
+	return s.withCORS(s.withAuth(mux))
+}
+
+// Start listens on 127.0.0.1 at the given port and serves in the background
+func (s *Server) Start(port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return err
+	}
+	s.httpServer = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
+	log.Printf("API server listening on %s", listener.Addr())
+	return nil
+}
+
+// Stop shuts the server down, closing open event streams
+func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// withCORS lets browser tools such as bookmarklets call the API from any
+// page. The token still protects every request.
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withAuth accepts the token as a bearer Authorization header. The event
+// stream also takes it as a token query parameter for EventSource clients
+// that can't set headers; other routes don't, so tokens stay out of URLs.
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" && r.URL.Path == eventsPath {
+			token = r.URL.Query().Get("token")
+		}
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeControllerError answers 404 for unknown items and 500 for anything
+// else that went wrong in the app
+func writeControllerError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, queue.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err.Error())
+}
+
+// handleGetQueue encodes every item under its lock first, as running
+// downloads keep changing them, and then writes the snapshot
+func (s *Server) handleGetQueue(w http.ResponseWriter, r *http.Request) {
+	items := s.controller.GetQueue()
+	snapshot := make([]json.RawMessage, 0, len(items))
+	for _, m := range items {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		snapshot = append(snapshot, data)
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	var req AddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.URL) == "" {
+		writeError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+	if req.Quality != "" && domain.ParseVideoQuality(req.Quality).String() != req.Quality {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown quality %q", req.Quality))
+		return
+	}
+	if err := req.PlaylistSelection.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := s.controller.AddToQueue(req.URL, req.Quality, req.Path, req.OnlyAudio, req.IsPlaylist, req.PlaylistSelection)
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.RemoveFromQueue(r.PathValue("id")); err != nil {
+		writeControllerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.PauseSingleDownload(r.PathValue("id")); err != nil {
+		writeControllerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	s.controller.StartDownloads()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams download events as Server-Sent Events, using the
+// frontend event names as SSE event types
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events := s.broker.Subscribe()
+	defer s.broker.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Payload)
+			if err != nil {
+				log.Printf("Error encoding %s event: %v", event.Name, err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data)
+			flusher.Flush()
+		}
+	}
+}
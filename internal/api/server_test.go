@@ -0,0 +1,327 @@
+package api_test
+
+import (
+	"bufio"
+	"byto/internal/api"
+	"byto/internal/domain"
+	"byto/internal/queue"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "secret"
+
+type fakeController struct {
+	mu      sync.Mutex
+	items   []*domain.Media
+	started int
+	paused  []string
+	added   []api.AddRequest
+	// removeErr is returned by RemoveFromQueue when set
+	removeErr error
+}
+
+func (c *fakeController) AddToQueue(url string, quality string, customPath string, onlyAudio bool, isPlaylist bool, playlistSelection domain.PlaylistSelection) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.added = append(c.added, api.AddRequest{URL: url, Quality: quality, Path: customPath, OnlyAudio: onlyAudio, IsPlaylist: isPlaylist, PlaylistSelection: playlistSelection})
+	return "new-id"
+}
+
+func (c *fakeController) GetQueue() []*domain.Media {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items
+}
+
+func (c *fakeController) StartDownloads() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started++
+}
+
+func (c *fakeController) PauseSingleDownload(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range c.items {
+		if m.ID == id {
+			c.paused = append(c.paused, id)
+			return nil
+		}
+	}
+	return queue.ErrNotFound
+}
+
+func (c *fakeController) RemoveFromQueue(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.removeErr != nil {
+		return c.removeErr
+	}
+	for i, m := range c.items {
+		if m.ID == id {
+			c.items = append(c.items[:i], c.items[i+1:]...)
+			return nil
+		}
+	}
+	return queue.ErrNotFound
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *fakeController, *api.Broker) {
+	t.Helper()
+	controller := &fakeController{items: []*domain.Media{{ID: "abc", URL: "https://example.com/v"}}}
+	broker := api.NewBroker()
+	srv := httptest.NewServer(api.NewServer(controller, broker, testToken).Handler())
+	t.Cleanup(srv.Close)
+	return srv, controller, broker
+}
+
+func request(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServer_RejectsMissingOrWrongToken(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/queue", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 with a wrong token, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_TokenQueryParameterOnlyOnEventStream(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/queue?token=" + testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 with the token in the query, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_EmptyTokenRejectsEverything(t *testing.T) {
+	srv := httptest.NewServer(api.NewServer(&fakeController{}, api.NewBroker(), "").Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/queue?token=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_GetQueue(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	resp := request(t, http.MethodGet, srv.URL+"/api/queue", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var items []domain.Media
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "abc" {
+		t.Errorf("unexpected queue %+v", items)
+	}
+}
+
+func TestServer_GetQueue_WhileItemChanges(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+	m := controller.GetQueue()[0]
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			m.AppendLog("line")
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if resp := request(t, http.MethodGet, srv.URL+"/api/queue", ""); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	}
+	<-done
+}
+
+func TestServer_AddToQueue(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+
+	body := `{"url": "https://example.com/p", "quality": "720p", "only_audio": true, "is_playlist": true, "playlist_selection": {"type": "items", "items": "1-3"}}`
+	resp := request(t, http.MethodPost, srv.URL+"/api/queue", body)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var created map[string]string
+	json.NewDecoder(resp.Body).Decode(&created)
+	if created["id"] != "new-id" {
+		t.Errorf("expected id new-id, got %v", created)
+	}
+
+	if len(controller.added) != 1 {
+		t.Fatalf("expected one added item, got %d", len(controller.added))
+	}
+	added := controller.added[0]
+	if added.URL != "https://example.com/p" || added.Quality != "720p" || !added.OnlyAudio || !added.IsPlaylist {
+		t.Errorf("unexpected add request %+v", added)
+	}
+	if added.PlaylistSelection.Items != "1-3" {
+		t.Errorf("expected items 1-3, got %q", added.PlaylistSelection.Items)
+	}
+}
+
+func TestServer_AddToQueue_RejectsBadRequests(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+
+	for _, body := range []string{`not json`, `{"url": "  "}`, `{"url": "https://example.com", "quality": "best"}`, `{"url": "https://example.com", "playlist_selection": {"type": "range", "start_index": 3, "end_index": 1}}`} {
+		resp := request(t, http.MethodPost, srv.URL+"/api/queue", body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("body %q: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+	if len(controller.added) != 0 {
+		t.Errorf("expected nothing added, got %+v", controller.added)
+	}
+}
+
+func TestServer_AddToQueue_EmptyQualityUsesDefault(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+
+	resp := request(t, http.MethodPost, srv.URL+"/api/queue", `{"url": "https://example.com/v"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if len(controller.added) != 1 || controller.added[0].Quality != "" {
+		t.Errorf("expected the quality left to the app defaults, got %+v", controller.added)
+	}
+}
+
+func TestServer_StartPauseRemove(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+
+	if resp := request(t, http.MethodPost, srv.URL+"/api/start", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("start: expected 204, got %d", resp.StatusCode)
+	}
+	if controller.started != 1 {
+		t.Errorf("expected downloads started once, got %d", controller.started)
+	}
+
+	if resp := request(t, http.MethodPost, srv.URL+"/api/queue/abc/pause", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("pause: expected 204, got %d", resp.StatusCode)
+	}
+	if len(controller.paused) != 1 || controller.paused[0] != "abc" {
+		t.Errorf("expected abc paused, got %v", controller.paused)
+	}
+	if resp := request(t, http.MethodPost, srv.URL+"/api/queue/missing/pause", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("pause unknown: expected 404, got %d", resp.StatusCode)
+	}
+
+	if resp := request(t, http.MethodDelete, srv.URL+"/api/queue/abc", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("remove: expected 204, got %d", resp.StatusCode)
+	}
+	if resp := request(t, http.MethodDelete, srv.URL+"/api/queue/abc", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second remove: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_ControllerFailureIsInternalError(t *testing.T) {
+	srv, controller, _ := newTestServer(t)
+	controller.removeErr = errors.New("disk full")
+
+	if resp := request(t, http.MethodDelete, srv.URL+"/api/queue/abc", ""); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Preflight(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodOptions, srv.URL+"/api/queue", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("expected CORS headers on preflight")
+	}
+}
+
+func TestServer_EventStream(t *testing.T) {
+	srv, _, broker := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/events?token="+testToken, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+
+	broker.Publish("download_status", map[string]interface{}{"id": "abc", "status": domain.Completed})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if lines[0] != "event: download_status" {
+		t.Errorf("unexpected event line %q", lines[0])
+	}
+	if lines[1] != `data: {"id":"abc","status":2}` {
+		t.Errorf("unexpected data line %q", lines[1])
+	}
+}
@@ -0,0 +1,197 @@
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Entry is one line of a yt-dlp download archive: the extractor name and the
+// video id, e.g. "youtube dQw4w9WgXcQ"
+type Entry struct {
+	Extractor string `json:"extractor"`
+	ID        string `json:"id"`
+}
+
+// Key returns the entry in yt-dlp's archive line format
+func (e Entry) Key() string {
+	return e.Extractor + " " + e.ID
+}
+
+// ErrInUse is returned when entries are removed while a download may be
+// appending to the archive
+var ErrInUse = errors.New("the download archive can't be changed while downloads are running")
+
+// Archive is the byto-managed yt-dlp download archive. yt-dlp appends to it
+// while downloading, and byto reads and prunes it. Pruning rewrites the
+// file, so it is refused while downloads hold the archive.
+type Archive struct {
+	filePath string
+	// users counts the downloads yt-dlp may be appending to the archive for
+	users int
+	mu    sync.Mutex
+}
+
+func getArchiveFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Error getting config dir: %v", err)
+		return "byto_archive.txt"
+	}
+
+	bytoDir := filepath.Join(configDir, "byto")
+	if err := os.MkdirAll(bytoDir, 0755); err != nil {
+		log.Printf("Error creating config dir: %v", err)
+		return "byto_archive.txt"
+	}
+
+	return filepath.Join(bytoDir, "archive.txt")
+}
+
+// NewArchive returns the archive stored in the byto config directory
+func NewArchive() *Archive {
+	return NewArchiveAt(getArchiveFilePath())
+}
+
+// NewArchiveAt returns an archive stored at the given file path
+func NewArchiveAt(filePath string) *Archive {
+	return &Archive{
+		filePath: filePath,
+	}
+}
+
+// FilePath returns the location passed to yt-dlp's --download-archive
+func (a *Archive) FilePath() string {
+	return a.filePath
+}
+
+// Acquire marks the archive as used by a download until Release is called
+func (a *Archive) Acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.users++
+}
+
+// Release undoes Acquire once the download is done with the archive
+func (a *Archive) Release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.users > 0 {
+		a.users--
+	}
+}
+
+// Entries returns all archive entries in file order. A missing archive has
+// no entries.
+func (a *Archive) Entries() ([]Entry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.read()
+}
+
+// Search returns the entries whose extractor or id contains the query,
+// ignoring case
+func (a *Archive) Search(query string) ([]Entry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return entries, nil
+	}
+
+	matches := make([]Entry, 0)
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Key()), query) {
+			matches = append(matches, e)
+		}
+	}
+	return matches, nil
+}
+
+// Remove deletes the given entries so the videos are downloaded again next
+// time. It returns how many entries were removed, or ErrInUse while
+// downloads hold the archive.
+func (a *Archive) Remove(entries []Entry) (int, error) {
+	remove := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		remove[e.Key()] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.users > 0 {
+		return 0, ErrInUse
+	}
+
+	current, err := a.read()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]Entry, 0, len(current))
+	for _, e := range current {
+		if !remove[e.Key()] {
+			kept = append(kept, e)
+		}
+	}
+
+	removed := len(current) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, a.write(kept)
+}
+
+// Clear removes every entry, or returns ErrInUse while downloads hold the
+// archive
+func (a *Archive) Clear() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.users > 0 {
+		return ErrInUse
+	}
+	return a.write(nil)
+}
+
+func (a *Archive) read() ([]Entry, error) {
+	file, err := os.Open(a.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		entries = append(entries, Entry{Extractor: fields[0], ID: fields[1]})
+	}
+	return entries, scanner.Err()
+}
+
+func (a *Archive) write(entries []Entry) error {
+	var sb strings.Builder
+	for _, e := range entries {
+		sb.WriteString(e.Key())
+		sb.WriteString("\n")
+	}
+
+	tmpPath := a.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, a.filePath)
+}
@@ -0,0 +1,139 @@
+package archive_test
+
+import (
+	"byto/internal/archive"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTempArchive(t *testing.T, content string) *archive.Archive {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "archive.txt")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write archive: %v", err)
+		}
+	}
+	return archive.NewArchiveAt(path)
+}
+
+func TestEntries_MissingFile_ReturnsEmpty(t *testing.T) {
+	a := newTempArchive(t, "")
+	entries, err := a.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestEntries_ParsesLines(t *testing.T) {
+	a := newTempArchive(t, "youtube abc123\nvimeo 42\n\nbroken-line\nyoutube  def456 \n")
+	entries, err := a.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []archive.Entry{
+		{Extractor: "youtube", ID: "abc123"},
+		{Extractor: "vimeo", ID: "42"},
+		{Extractor: "youtube", ID: "def456"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("entry[%d] = %+v, want %+v", i, entries[i], expected[i])
+		}
+	}
+}
+
+func TestEntry_Key(t *testing.T) {
+	e := archive.Entry{Extractor: "youtube", ID: "abc"}
+	if e.Key() != "youtube abc" {
+		t.Errorf("expected 'youtube abc', got %q", e.Key())
+	}
+}
+
+func TestSearch(t *testing.T) {
+	a := newTempArchive(t, "youtube abc123\nvimeo 42\nyoutube XYZ\n")
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{name: "empty query returns all", query: "", expected: 3},
+		{name: "by extractor", query: "youtube", expected: 2},
+		{name: "by id", query: "42", expected: 1},
+		{name: "case insensitive", query: "xyz", expected: 1},
+		{name: "no match", query: "dailymotion", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := a.Search(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != tt.expected {
+				t.Errorf("expected %d entries, got %v", tt.expected, entries)
+			}
+		})
+	}
+}
+
+func TestRemove_PrunesEntries(t *testing.T) {
+	a := newTempArchive(t, "youtube a\nyoutube b\nvimeo c\n")
+	removed, err := a.Remove([]archive.Entry{{Extractor: "youtube", ID: "b"}, {Extractor: "vimeo", ID: "missing"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 removed, got %d", removed)
+	}
+
+	entries, _ := a.Entries()
+	if len(entries) != 2 || entries[0].ID != "a" || entries[1].ID != "c" {
+		t.Errorf("unexpected entries after remove: %v", entries)
+	}
+}
+
+func TestRemove_NothingMatched_LeavesFileUntouched(t *testing.T) {
+	a := newTempArchive(t, "youtube a\n")
+	removed, err := a.Remove([]archive.Entry{{Extractor: "youtube", ID: "z"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("expected 0 removed, got %d", removed)
+	}
+}
+
+func TestClear(t *testing.T) {
+	a := newTempArchive(t, "youtube a\nyoutube b\n")
+	if err := a.Clear(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, _ := a.Entries()
+	if len(entries) != 0 {
+		t.Errorf("expected empty archive, got %v", entries)
+	}
+}
+
+func TestRemove_RefusedWhileInUse(t *testing.T) {
+	a := newTempArchive(t, "youtube a\n")
+	a.Acquire()
+	if _, err := a.Remove([]archive.Entry{{Extractor: "youtube", ID: "a"}}); !errors.Is(err, archive.ErrInUse) {
+		t.Errorf("expected ErrInUse, got %v", err)
+	}
+	if err := a.Clear(); !errors.Is(err, archive.ErrInUse) {
+		t.Errorf("expected ErrInUse from Clear, got %v", err)
+	}
+
+	a.Release()
+	if removed, err := a.Remove([]archive.Entry{{Extractor: "youtube", ID: "a"}}); err != nil || removed != 1 {
+		t.Errorf("expected the entry removed once released, got %d, %v", removed, err)
+	}
+}
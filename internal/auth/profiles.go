@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"byto/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrProfileNotFound is returned when no profile has the requested id
+var ErrProfileNotFound = errors.New("auth profile not found")
+
+// Store keeps the authentication profiles in a JSON file in the byto config
+// directory. The file is read on every call so the desktop app and the
+// command-line interface see each other's changes.
+type Store struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func getProfilesFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Error getting config dir: %v", err)
+		return "byto_auth_profiles.json"
+	}
+
+	bytoDir := filepath.Join(configDir, "byto")
+	if err := os.MkdirAll(bytoDir, 0755); err != nil {
+		log.Printf("Error creating config dir: %v", err)
+		return "byto_auth_profiles.json"
+	}
+
+	return filepath.Join(bytoDir, "auth_profiles.json")
+}
+
+// NewStore returns the profiles stored in the byto config directory
+func NewStore() *Store {
+	return NewStoreAt(getProfilesFilePath())
+}
+
+// NewStoreAt returns profiles stored at the given file path
+func NewStoreAt(filePath string) *Store {
+	return &Store{
+		filePath: filePath,
+	}
+}
+
+// Profiles returns all profiles in the order they were created. A missing
+// file has no profiles.
+func (s *Store) Profiles() ([]domain.AuthProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Get returns the profile with the given id
+func (s *Store) Get(id string) (domain.AuthProfile, error) {
+	profiles, err := s.Profiles()
+	if err != nil {
+		return domain.AuthProfile{}, err
+	}
+	for _, p := range profiles {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return domain.AuthProfile{}, ErrProfileNotFound
+}
+
+// Find returns the profile whose id or name is the given key
+func (s *Store) Find(key string) (domain.AuthProfile, error) {
+	profiles, err := s.Profiles()
+	if err != nil {
+		return domain.AuthProfile{}, err
+	}
+	for _, p := range profiles {
+		if p.ID == key || p.Name == key {
+			return p, nil
+		}
+	}
+	return domain.AuthProfile{}, fmt.Errorf("%w: %q", ErrProfileNotFound, key)
+}
+
+// Save adds a profile, or replaces the one with the same id. Profiles
+// without an id get a new one.
+func (s *Store) Save(profile domain.AuthProfile) (domain.AuthProfile, error) {
+	if err := profile.Validate(); err != nil {
+		return profile, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profiles, err := s.read()
+	if err != nil {
+		return profile, err
+	}
+
+	if profile.ID == "" {
+		profile.ID = uuid.New().String()
+	}
+	replaced := false
+	for i := range profiles {
+		if profiles[i].ID == profile.ID {
+			profiles[i] = profile
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, profile)
+	}
+	return profile, s.write(profiles)
+}
+
+// Remove deletes the profile with the given id
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profiles, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range profiles {
+		if profiles[i].ID == id {
+			return s.write(append(profiles[:i], profiles[i+1:]...))
+		}
+	}
+	return ErrProfileNotFound
+}
+
+// Resolve picks the profile for a download: the item's own profile when it
+// has one, otherwise the first profile listing the URL's site. ok is false
+// when neither applies.
+func (s *Store) Resolve(profileID string, url string) (profile domain.AuthProfile, ok bool, err error) {
+	if profileID != "" {
+		profile, err := s.Get(profileID)
+		if err != nil {
+			return profile, false, err
+		}
+		return profile, true, nil
+	}
+
+	profiles, err := s.Profiles()
+	if err != nil {
+		return domain.AuthProfile{}, false, err
+	}
+	for _, p := range profiles {
+		if p.MatchesURL(url) {
+			return p, true, nil
+		}
+	}
+	return domain.AuthProfile{}, false, nil
+}
+
+func (s *Store) read() ([]domain.AuthProfile, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return []domain.AuthProfile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []domain.AuthProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.filePath, err)
+	}
+	return profiles, nil
+}
+
+func (s *Store) write(profiles []domain.AuthProfile) error {
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Profiles point at session cookies, keep them private to the user
+	if err := os.WriteFile(s.filePath, data, 0600); err != nil {
+		return err
+	}
+	log.Printf("Auth profiles saved to %s", s.filePath)
+	return nil
+}
@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"byto/internal/auth"
+	"byto/internal/domain"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTempStore(t *testing.T) *auth.Store {
+	t.Helper()
+	return auth.NewStoreAt(filepath.Join(t.TempDir(), "auth_profiles.json"))
+}
+
+func TestProfiles_MissingFile_ReturnsEmpty(t *testing.T) {
+	profiles, err := newTempStore(t).Profiles()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("expected no profiles, got %v", profiles)
+	}
+}
+
+func TestSave_AssignsIDAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth_profiles.json")
+	s := auth.NewStoreAt(path)
+
+	saved, err := s.Save(domain.AuthProfile{Name: "YouTube", Sites: []string{"youtube.com"}, Browser: "firefox"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.ID == "" {
+		t.Fatal("expected an id to be assigned")
+	}
+
+	got, err := auth.NewStoreAt(path).Get(saved.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "YouTube" || got.Browser != "firefox" {
+		t.Errorf("unexpected profile %+v", got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		t.Errorf("expected the file to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSave_ReplacesExisting(t *testing.T) {
+	s := newTempStore(t)
+	saved, _ := s.Save(domain.AuthProfile{Name: "old", CookieFile: "/a.txt"})
+
+	saved.Name = "new"
+	if _, err := s.Save(saved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	profiles, _ := s.Profiles()
+	if len(profiles) != 1 || profiles[0].Name != "new" {
+		t.Errorf("expected one renamed profile, got %+v", profiles)
+	}
+}
+
+func TestSave_RejectsInvalidProfile(t *testing.T) {
+	s := newTempStore(t)
+	if _, err := s.Save(domain.AuthProfile{Name: "none"}); err == nil {
+		t.Error("expected an error for a profile without cookies")
+	}
+	profiles, _ := s.Profiles()
+	if len(profiles) != 0 {
+		t.Errorf("expected nothing saved, got %+v", profiles)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	s := newTempStore(t)
+	a, _ := s.Save(domain.AuthProfile{Name: "a", CookieFile: "/a.txt"})
+	b, _ := s.Save(domain.AuthProfile{Name: "b", CookieFile: "/b.txt"})
+
+	if err := s.Remove(a.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	profiles, _ := s.Profiles()
+	if len(profiles) != 1 || profiles[0].ID != b.ID {
+		t.Errorf("expected only b left, got %+v", profiles)
+	}
+	if err := s.Remove(a.ID); !errors.Is(err, auth.ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestFind_ByIDOrName(t *testing.T) {
+	s := newTempStore(t)
+	saved, _ := s.Save(domain.AuthProfile{Name: "work", Browser: "chrome"})
+
+	for _, key := range []string{saved.ID, "work"} {
+		got, err := s.Find(key)
+		if err != nil || got.ID != saved.ID {
+			t.Errorf("Find(%q) = %+v, %v", key, got, err)
+		}
+	}
+	if _, err := s.Find("missing"); !errors.Is(err, auth.ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s := newTempStore(t)
+	yt, _ := s.Save(domain.AuthProfile{Name: "yt", Sites: []string{"youtube.com"}, Browser: "firefox"})
+	file, _ := s.Save(domain.AuthProfile{Name: "file", CookieFile: "/c.txt"})
+
+	got, ok, err := s.Resolve("", "https://www.youtube.com/watch?v=1")
+	if err != nil || !ok || got.ID != yt.ID {
+		t.Errorf("expected the site profile, got %+v, %v, %v", got, ok, err)
+	}
+
+	got, ok, err = s.Resolve(file.ID, "https://www.youtube.com/watch?v=1")
+	if err != nil || !ok || got.ID != file.ID {
+		t.Errorf("expected the explicit profile, got %+v, %v, %v", got, ok, err)
+	}
+
+	if _, ok, err := s.Resolve("", "https://vimeo.com/1"); ok || err != nil {
+		t.Errorf("expected no profile for an unmatched site, got %v, %v", ok, err)
+	}
+
+	if _, _, err := s.Resolve("gone", "https://vimeo.com/1"); !errors.Is(err, auth.ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
@@ -3,10 +3,13 @@ package builder
 import (
 	"byto/internal/domain"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 )
 
 type YTDLPBuilder struct {
@@ -59,6 +62,27 @@ func (y *YTDLPBuilder) Newline() *YTDLPBuilder {
 	return y
 }
 
+// PrintToFile appends the template to the file each time yt-dlp reaches the
+// given stage, e.g. "after_move" once a video is in its final place. Unlike
+// --print it keeps the normal output.
+func (y *YTDLPBuilder) PrintToFile(when string, template string, file string) *YTDLPBuilder {
+	if template == "" || file == "" {
+		return y
+	}
+	if when != "" {
+		template = when + ":" + template
+	}
+	y.args = append(y.args, "--print-to-file", template, file)
+	return y
+}
+
+// ProbeJSON makes yt-dlp print the media metadata as a single JSON document
+// instead of downloading. Playlists are listed flat so probing stays fast.
+func (y *YTDLPBuilder) ProbeJSON() *YTDLPBuilder {
+	y.args = append(y.args, "--dump-single-json", "--flat-playlist", "--no-warnings")
+	return y
+}
+
 func (y *YTDLPBuilder) Video(quality domain.VideoQuality) *YTDLPBuilder {
 	// Use format selection with fallback to best available
 	// "bestvideo[height<=X]+bestaudio/best[height<=X]/best" means:
@@ -84,13 +108,194 @@ func (y *YTDLPBuilder) Video(quality domain.VideoQuality) *YTDLPBuilder {
 	return y
 }
 
+// Format selects formats from a FormatSelection. An explicit format id is
+// passed as-is, merged with the best audio when it has none; otherwise
+// the preferences become a height-capped -f expression plus a -S sort order.
+func (y *YTDLPBuilder) Format(selection domain.FormatSelection) *YTDLPBuilder {
+	if err := selection.Validate(); err != nil {
+		return y
+	}
+
+	if selection.FormatID != "" {
+		id := selection.FormatID
+		if strings.ContainsAny(id, "+/") || !selection.MissingAudio() {
+			y.args = append(y.args, "-f", id)
+		} else {
+			y.args = append(y.args, "-f", fmt.Sprintf("%s+bestaudio/%s", id, id))
+		}
+	} else {
+		if selection.MaxHeight > 0 {
+			h := selection.MaxHeight
+			y.args = append(y.args, "-f", fmt.Sprintf("bestvideo[height<=%d]+bestaudio/best[height<=%d]/best", h, h))
+		} else {
+			y.args = append(y.args, "-f", "bestvideo+bestaudio/best")
+		}
+
+		var sort []string
+		if selection.HDR {
+			sort = append(sort, "hdr:12")
+		}
+		if selection.MaxHeight > 0 {
+			sort = append(sort, fmt.Sprintf("res:%d", selection.MaxHeight))
+		}
+		if selection.FPS > 0 {
+			sort = append(sort, fmt.Sprintf("fps:%d", selection.FPS))
+		}
+		switch selection.Codec {
+		case domain.CodecAV1:
+			sort = append(sort, "vcodec:av01")
+		case domain.CodecVP9:
+			sort = append(sort, "vcodec:vp9")
+		case domain.CodecH265:
+			sort = append(sort, "vcodec:h265")
+		case domain.CodecH264:
+			sort = append(sort, "vcodec:h264")
+		}
+		switch selection.Container {
+		case domain.ContainerMP4:
+			sort = append(sort, "ext:mp4:m4a")
+		case domain.ContainerWebM:
+			sort = append(sort, "ext:webm:webm")
+		}
+		if len(sort) > 0 {
+			y.args = append(y.args, "-S", strings.Join(sort, ","))
+		}
+	}
+
+	if selection.Container != domain.ContainerAny {
+		y.args = append(y.args, "--merge-output-format", string(selection.Container))
+	}
+	return y
+}
+
+// FormatID downloads exactly the format with the given id, e.g. an audio
+// format picked for an audio-only download
+func (y *YTDLPBuilder) FormatID(id string) *YTDLPBuilder {
+	if err := (domain.FormatSelection{FormatID: id}).Validate(); err != nil || id == "" {
+		return y
+	}
+	y.args = append(y.args, "-f", id)
+	return y
+}
+
 func (y *YTDLPBuilder) Audio() *YTDLPBuilder {
 	y.args = append(y.args, "-f", "bestaudio/best")
 	return y
 }
 
+// Subtitles downloads subtitles in the requested languages and either embeds
+// them in the output file or writes them next to it
+func (y *YTDLPBuilder) Subtitles(subtitles domain.SubtitleOptions) *YTDLPBuilder {
+	if !subtitles.Enabled || subtitles.Validate() != nil {
+		return y
+	}
+
+	switch subtitles.Source {
+	case domain.SubtitleAuto:
+		y.args = append(y.args, "--write-auto-subs")
+	case domain.SubtitleBoth:
+		y.args = append(y.args, "--write-subs", "--write-auto-subs")
+	default:
+		y.args = append(y.args, "--write-subs")
+	}
+
+	y.args = append(y.args, "--sub-langs", strings.Join(subtitles.ResolvedLanguages(), ","))
+
+	format := subtitles.Format
+	if format == "" {
+		format = domain.SubtitleSRT
+	}
+	y.args = append(y.args, "--sub-format", string(format)+"/best", "--convert-subs", string(format))
+
+	if subtitles.Embed {
+		y.args = append(y.args, "--embed-subs")
+	}
+	return y
+}
+
+// ExtractAudio remuxes audio-only downloads into a standalone audio file
+// (e.g. .opus or .m4a) instead of keeping the site's container, so tags and
+// cover art can be embedded
+func (y *YTDLPBuilder) ExtractAudio() *YTDLPBuilder {
+	y.args = append(y.args, "--extract-audio")
+	return y
+}
+
+// AudioProfile extracts the audio and converts it to the profile's codec and
+// bitrate. The original codec keeps the audio as-is.
+func (y *YTDLPBuilder) AudioProfile(profile domain.AudioProfile) *YTDLPBuilder {
+	if err := profile.Validate(); err != nil {
+		return y
+	}
+
+	y.ExtractAudio()
+	if profile.Codec == domain.AudioOriginal {
+		return y
+	}
+	y.args = append(y.args, "--audio-format", string(profile.Codec))
+	if !profile.IsLossless() {
+		quality := "0" // best VBR quality
+		if profile.Bitrate > 0 {
+			quality = fmt.Sprintf("%dK", profile.Bitrate)
+		}
+		y.args = append(y.args, "--audio-quality", quality)
+	}
+	return y
+}
+
+// FfmpegLocation points yt-dlp at the ffmpeg binary to use
+func (y *YTDLPBuilder) FfmpegLocation(path string) *YTDLPBuilder {
+	if path != "" {
+		y.args = append(y.args, "--ffmpeg-location", path)
+	}
+	return y
+}
+
+// EmbedThumbnail embeds the thumbnail as cover art, converted to jpg since
+// most containers can't hold webp
+func (y *YTDLPBuilder) EmbedThumbnail() *YTDLPBuilder {
+	y.args = append(y.args, "--embed-thumbnail", "--convert-thumbnails", "jpg")
+	return y
+}
+
+// EmbedMetadata writes title, artist, date etc. as container tags
+// (ID3 for mp3, Vorbis comments for opus/flac)
+func (y *YTDLPBuilder) EmbedMetadata() *YTDLPBuilder {
+	y.args = append(y.args, "--embed-metadata")
+	return y
+}
+
+// EmbedChapters adds chapter markers to the output file
+func (y *YTDLPBuilder) EmbedChapters() *YTDLPBuilder {
+	y.args = append(y.args, "--embed-chapters")
+	return y
+}
+
+// Embed applies all enabled embed options
+func (y *YTDLPBuilder) Embed(embed domain.EmbedOptions) *YTDLPBuilder {
+	if embed.Thumbnail {
+		y.EmbedThumbnail()
+	}
+	if embed.Metadata {
+		y.EmbedMetadata()
+	}
+	if embed.Chapters {
+		y.EmbedChapters()
+	}
+	return y
+}
+
 func (y *YTDLPBuilder) DownloadPath(path string) *YTDLPBuilder {
-	y.args = append(y.args, "-o", path+"/%(title).100s.%(ext)s")
+	return y.OutputTemplate(path, domain.DefaultOutputTemplate)
+}
+
+// OutputTemplate saves files under path using a yt-dlp output template.
+// Invalid templates fall back to the default.
+func (y *YTDLPBuilder) OutputTemplate(path string, template string) *YTDLPBuilder {
+	if template == "" || domain.ValidateOutputTemplate(template) != nil {
+		template = domain.DefaultOutputTemplate
+	}
+	y.args = append(y.args, "-o", path+"/"+domain.NormalizeOutputTemplate(template))
 	return y
 }
 
@@ -109,11 +314,96 @@ func (y *YTDLPBuilder) URL(url string) *YTDLPBuilder {
 	return y
 }
 
+// Resume continues partially downloaded files and never overwrites files
+// that are already complete
+func (y *YTDLPBuilder) Resume() *YTDLPBuilder {
+	y.args = append(y.args, "--continue", "--no-overwrites")
+	return y
+}
+
+// DownloadArchive records downloaded videos in the archive file and skips
+// videos that are already recorded there
+func (y *YTDLPBuilder) DownloadArchive(path string) *YTDLPBuilder {
+	if path != "" {
+		y.args = append(y.args, "--download-archive", path)
+	}
+	return y
+}
+
+// RateLimit caps the download rate in bytes per second. Zero or less leaves
+// it unlimited.
+func (y *YTDLPBuilder) RateLimit(bytesPerSecond int64) *YTDLPBuilder {
+	if bytesPerSecond > 0 {
+		y.args = append(y.args, "--limit-rate", strconv.FormatInt(bytesPerSecond, 10))
+	}
+	return y
+}
+
+// Network applies the proxy and connection settings. Settings that don't
+// validate are left out.
+func (y *YTDLPBuilder) Network(network domain.NetworkSettings) *YTDLPBuilder {
+	if err := network.Validate(); err != nil {
+		return y
+	}
+	if proxy, _ := network.Proxy(); proxy != nil {
+		y.args = append(y.args, "--proxy", proxy.String())
+	}
+	if network.SourceAddress != "" {
+		y.args = append(y.args, "--source-address", network.SourceAddress)
+	}
+	switch network.IPVersion {
+	case domain.IPv4:
+		y.args = append(y.args, "--force-ipv4")
+	case domain.IPv6:
+		y.args = append(y.args, "--force-ipv6")
+	}
+	if network.SocketTimeoutSeconds > 0 {
+		y.args = append(y.args, "--socket-timeout", strconv.Itoa(network.SocketTimeoutSeconds))
+	}
+	return y
+}
+
+// Cookies loads cookies from a Netscape-format cookie file
+func (y *YTDLPBuilder) Cookies(path string) *YTDLPBuilder {
+	if path != "" {
+		y.args = append(y.args, "--cookies", path)
+	}
+	return y
+}
+
+// CookiesFromBrowser reads cookies from an installed browser, optionally
+// from one of its profiles
+func (y *YTDLPBuilder) CookiesFromBrowser(browser string, profile string) *YTDLPBuilder {
+	if browser == "" {
+		return y
+	}
+	if profile != "" {
+		browser += ":" + profile
+	}
+	y.args = append(y.args, "--cookies-from-browser", browser)
+	return y
+}
+
+// Auth passes the credentials of an authentication profile
+func (y *YTDLPBuilder) Auth(profile domain.AuthProfile) *YTDLPBuilder {
+	if profile.CookieFile != "" {
+		return y.Cookies(profile.CookieFile)
+	}
+	return y.CookiesFromBrowser(profile.Browser, profile.BrowserProfile)
+}
+
 func (y *YTDLPBuilder) Update() *YTDLPBuilder {
 	y.args = append(y.args, "--update")
 	return y
 }
 
+// NoPlaylist downloads only the video of links that point to a video inside
+// a playlist. Links to a playlist itself are still downloaded whole.
+func (y *YTDLPBuilder) NoPlaylist() *YTDLPBuilder {
+	y.args = append(y.args, "--no-playlist")
+	return y
+}
+
 func (y *YTDLPBuilder) Playlist(playlist domain.PlaylistSelection) *YTDLPBuilder {
 	if err := playlist.Validate(); err != nil {
 		return y
@@ -130,3 +420,19 @@ func (y *YTDLPBuilder) Playlist(playlist domain.PlaylistSelection) *YTDLPBuilder
 func (y *YTDLPBuilder) Build() []string {
 	return y.args
 }
+
+// Redacted returns the arguments like Build, with the proxy password masked
+// so the command line can be logged
+func (y *YTDLPBuilder) Redacted() []string {
+	args := make([]string, len(y.args))
+	copy(args, y.args)
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] != "--proxy" {
+			continue
+		}
+		if proxy, err := url.Parse(args[i+1]); err == nil && proxy.User != nil {
+			args[i+1] = proxy.Redacted()
+		}
+	}
+	return args
+}
@@ -0,0 +1,130 @@
+package clipboard_test
+
+import (
+	"byto/internal/clipboard"
+	"byto/internal/domain"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSiteMatcher(t *testing.T) {
+	m := clipboard.SiteMatcher{Sites: []string{"youtube.com", "youtu.be", " .Vimeo.com"}}
+	tests := []struct {
+		text    string
+		wantURL string
+		wantOK  bool
+	}{
+		{"https://www.youtube.com/watch?v=1", "https://www.youtube.com/watch?v=1", true},
+		{"  https://youtu.be/1\n", "https://youtu.be/1", true},
+		{"http://vimeo.com/1", "http://vimeo.com/1", true},
+		{"https://player.vimeo.com/video/1", "https://player.vimeo.com/video/1", true},
+		{"https://notyoutube.com/watch?v=1", "", false},
+		{"https://example.com/video.mp4", "", false},
+		{"ftp://youtube.com/1", "", false},
+		{"youtube.com/watch?v=1", "", false},
+		{"watch this https://youtu.be/1", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		url, ok := m.Match(tt.text)
+		if url != tt.wantURL || ok != tt.wantOK {
+			t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.text, url, ok, tt.wantURL, tt.wantOK)
+		}
+	}
+}
+
+func TestSiteMatcher_DefaultSites(t *testing.T) {
+	m := clipboard.SiteMatcher{Sites: domain.ClipboardSettings{}.WatchedSites()}
+	if _, ok := m.Match("https://www.youtube.com/watch?v=1"); !ok {
+		t.Error("expected youtube links to match the default sites")
+	}
+}
+
+// fakeClipboard lets tests copy text and collects the links the watcher
+// reports
+type fakeClipboard struct {
+	text  string
+	err   error
+	known map[string]bool
+	found []string
+}
+
+func (c *fakeClipboard) watcher(m clipboard.Matcher) *clipboard.Watcher {
+	return clipboard.NewWatcher(
+		func() (string, error) { return c.text, c.err },
+		m,
+		func(url string) bool { return c.known[url] },
+		func(url string) { c.found = append(c.found, url) },
+	)
+}
+
+var youtube = clipboard.SiteMatcher{Sites: []string{"youtube.com"}}
+
+func TestWatcher_ReportsNewlyCopiedLinks(t *testing.T) {
+	c := &fakeClipboard{text: "https://youtube.com/watch?v=old"}
+	w := c.watcher(youtube)
+
+	w.Check()
+	if len(c.found) != 0 {
+		t.Fatalf("expected the text copied before starting to be ignored, got %v", c.found)
+	}
+
+	c.text = "https://youtube.com/watch?v=1"
+	w.Check()
+	w.Check()
+	c.text = "some notes"
+	w.Check()
+	c.text = "https://youtube.com/watch?v=2"
+	w.Check()
+
+	want := []string{"https://youtube.com/watch?v=1", "https://youtube.com/watch?v=2"}
+	if !reflect.DeepEqual(c.found, want) {
+		t.Errorf("expected %v, got %v", want, c.found)
+	}
+}
+
+func TestWatcher_SkipsKnownLinks(t *testing.T) {
+	c := &fakeClipboard{known: map[string]bool{"https://youtube.com/watch?v=queued": true}}
+	w := c.watcher(youtube)
+	w.Check()
+
+	c.text = "https://youtube.com/watch?v=queued"
+	w.Check()
+	if len(c.found) != 0 {
+		t.Errorf("expected a known link to be skipped, got %v", c.found)
+	}
+}
+
+func TestWatcher_ReadErrorKeepsLastText(t *testing.T) {
+	c := &fakeClipboard{}
+	w := c.watcher(youtube)
+	w.Check()
+
+	c.err = errors.New("clipboard busy")
+	c.text = "https://youtube.com/watch?v=1"
+	w.Check()
+	c.err = nil
+	w.Check()
+	if len(c.found) != 1 {
+		t.Errorf("expected the link once the clipboard could be read, got %v", c.found)
+	}
+}
+
+func TestWatcher_SetMatcher(t *testing.T) {
+	c := &fakeClipboard{}
+	w := c.watcher(youtube)
+	w.Check()
+
+	w.SetMatcher(clipboard.MatcherFunc(func(text string) (string, bool) {
+		return text, strings.HasPrefix(text, "magnet:")
+	}))
+	c.text = "https://youtube.com/watch?v=1"
+	w.Check()
+	c.text = "magnet:?xt=1"
+	w.Check()
+	if !reflect.DeepEqual(c.found, []string{"magnet:?xt=1"}) {
+		t.Errorf("expected only the new matcher's link, got %v", c.found)
+	}
+}
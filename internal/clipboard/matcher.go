@@ -0,0 +1,44 @@
+package clipboard
+
+import (
+	"byto/internal/domain"
+	"net/url"
+	"strings"
+)
+
+// Matcher recognizes the media links worth picking up in copied text
+type Matcher interface {
+	// Match returns the link found in the text and whether there was one
+	Match(text string) (string, bool)
+}
+
+// MatcherFunc adapts a function to a Matcher
+type MatcherFunc func(text string) (string, bool)
+
+func (f MatcherFunc) Match(text string) (string, bool) {
+	return f(text)
+}
+
+// SiteMatcher matches copied text that is a single http or https link to
+// one of Sites, see domain.HostMatches
+type SiteMatcher struct {
+	Sites []string
+}
+
+func (m SiteMatcher) Match(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return "", false
+	}
+
+	u, err := url.Parse(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	for _, site := range m.Sites {
+		if domain.HostMatches(u.Hostname(), site) {
+			return text, true
+		}
+	}
+	return "", false
+}
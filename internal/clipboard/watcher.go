@@ -0,0 +1,117 @@
+package clipboard
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often the watcher reads the clipboard
+const DefaultInterval = time.Second
+
+// Watcher polls the clipboard and reports the media links copied while it
+// runs. Links that are already known, e.g. queued or downloaded before, are
+// left out.
+type Watcher struct {
+	read     func() (string, error)
+	matcher  Matcher
+	known    func(url string) bool
+	found    func(url string)
+	interval time.Duration
+	// last is the clipboard text seen by the previous check, so a link is
+	// reported once per copy rather than on every check
+	last   string
+	primed bool
+	mu     sync.Mutex
+	stop   chan struct{}
+}
+
+// NewWatcher returns a watcher reading the clipboard with read. found is
+// called with every new link the matcher recognizes that known doesn't.
+func NewWatcher(read func() (string, error), matcher Matcher, known func(url string) bool, found func(url string)) *Watcher {
+	return &Watcher{
+		read:     read,
+		matcher:  matcher,
+		known:    known,
+		found:    found,
+		interval: DefaultInterval,
+	}
+}
+
+// SetMatcher changes how links are recognized
+func (w *Watcher) SetMatcher(matcher Matcher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.matcher = matcher
+}
+
+// Check reads the clipboard once. The first check only remembers the text,
+// so whatever was copied before the watcher started isn't reported.
+func (w *Watcher) Check() {
+	text, err := w.read()
+	if err != nil {
+		log.Printf("Error reading the clipboard: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	changed := w.primed && text != w.last
+	w.last = text
+	w.primed = true
+	matcher := w.matcher
+	w.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	url, ok := matcher.Match(text)
+	if !ok || w.known(url) {
+		return
+	}
+	log.Printf("Media link copied: %s", url)
+	w.found(url)
+}
+
+// Start runs the watcher in the background until Stop is called
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	if w.stop != nil {
+		w.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	w.stop = stop
+	w.primed = false
+	w.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		w.Check()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop ends the background polling
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// Running reports whether the watcher was started
+func (w *Watcher) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stop != nil
+}
@@ -6,21 +6,42 @@ import (
 	"byto/internal/domain"
 	"byto/internal/parser"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrFfmpegMissing is returned when an item needs ffmpeg for post-processing
+// but it isn't installed
+var ErrFfmpegMissing = errors.New("ffmpeg is required to convert or embed into this file but it is not installed; install it from the settings and try again")
+
+// DefaultStopTimeout is how long a paused yt-dlp process is given to exit
+// after being interrupted before it is killed.
+const DefaultStopTimeout = 10 * time.Second
+
+// ErrRestart is the cancel cause of a download that is stopped only to be
+// started again right away, e.g. with a new rate limit. The item's playlist
+// entries keep their status then.
+var ErrRestart = errors.New("download restarted")
+
 type DownloadCommand struct {
 	Builder *builder.YTDLPBuilder
+	// Resume continues from the .part files left by a paused download and
+	// keeps the progress already recorded on the media
+	Resume bool
+	// StopTimeout overrides DefaultStopTimeout when non-zero
+	StopTimeout time.Duration
 }
 
 func (c *DownloadCommand) Execute(args any) error {
-	log.Printf("DownloadCommand: Starting execution with args: %+v", args)
-
 	if c.Builder == nil {
 		err := fmt.Errorf("YTDLPBuilder is nil")
 		log.Printf("DownloadCommand: Builder validation failed: %v", err)
@@ -33,12 +54,27 @@ func (c *DownloadCommand) Execute(args any) error {
 		log.Printf("DownloadCommand: Argument validation failed: %v", err)
 		return err
 	}
-	log.Printf("DownloadCommand: Processing media: %s", media.URL)
+	log.Printf("DownloadCommand: Processing media %s: %s", media.ID, media.URL)
 
-	c.Builder.ProgressTemplate("[byto] %(info.title)s [downloaded] %(progress.downloaded_bytes)s [total] %(progress.total_bytes)s [frag] %(progress.fragment_index)s [frags] %(progress.fragment_count)s")
+	c.Builder.ProgressTemplate("[byto] %(info.title)s [downloaded] %(progress.downloaded_bytes)s [total] %(progress.total_bytes)s [frag] %(progress.fragment_index)s [frags] %(progress.fragment_count)s [speed] %(progress.speed)s [eta] %(progress.eta)s [elapsed] %(progress.elapsed)s")
 	c.Builder.Newline() // Force newline after each progress update
+	if c.Resume {
+		c.Builder.Resume()
+	} else {
+		media.ClearOutputFiles()
+	}
 	log.Printf("DownloadCommand: Configured YTDLP builder progress template.")
 
+	// yt-dlp appends the final path of each video to this file once it is
+	// moved into place, after merging and converting
+	filesPath, err := createOutputFilesList()
+	if err != nil {
+		log.Printf("DownloadCommand: Output files won't be recorded: %v", err)
+	} else {
+		defer os.Remove(filesPath)
+		c.Builder.PrintToFile("after_move", "%(filepath)s", filesPath)
+	}
+
 	ucmd := c.Builder.Build()
 	ytdlpPath := c.Builder.GetYtDlpPath()
 
@@ -50,19 +86,24 @@ func (c *DownloadCommand) Execute(args any) error {
 
 	cmd := exec.CommandContext(ctx, ytdlpPath, ucmd...)
 	HideWindow(cmd) // Hide console window on Windows
-	log.Printf("DownloadCommand: Executing command: %s %v", ytdlpPath, ucmd)
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log.Printf("DownloadCommand: Failed to get stdout pipe: %v", err)
-		return err
+	// Pausing cancels the context. Interrupt yt-dlp first so it keeps its
+	// .part files, and only kill it if it hasn't exited after the timeout.
+	cmd.Cancel = func() error {
+		return interruptProcess(cmd.Process)
 	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		log.Printf("DownloadCommand: Failed to get stderr pipe: %v", err)
-		return err
+	cmd.WaitDelay = c.StopTimeout
+	if cmd.WaitDelay <= 0 {
+		cmd.WaitDelay = DefaultStopTimeout
 	}
+	log.Printf("DownloadCommand: Executing command: %s %v", ytdlpPath, c.Builder.Redacted())
+
+	// exec copies the output into these pipes and Wait only returns once
+	// the copying is done (or WaitDelay expires), so no line is lost
+	stdout, stdoutWriter := io.Pipe()
+	stderr, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
 
 	if err := cmd.Start(); err != nil {
 		log.Printf("DownloadCommand: Failed to start yt-dlp command: %v", err)
@@ -71,6 +112,44 @@ func (c *DownloadCommand) Execute(args any) error {
 	log.Printf("DownloadCommand: yt-dlp command started successfully.")
 
 	p := parser.YTDLPDownloadParser{}
+	subtitleParser := parser.YTDLPSubtitleParser{}
+	postProcessParser := parser.YTDLPPostProcessParser{}
+	archiveParser := parser.YTDLPArchiveParser{}
+	playlistParser := parser.YTDLPPlaylistParser{}
+
+	// Set while yt-dlp post-processes a finished download, so the item shows
+	// a processing state instead of sitting at 100%
+	var processing atomic.Bool
+
+	// Track whether anything was downloaded, so an item whose videos were
+	// all in the download archive is reported as skipped
+	var downloadedAny atomic.Bool
+	var skippedAny atomic.Bool
+	// Set once a playlist video failed, as yt-dlp then exits with an error
+	// even though it finished the other videos
+	var entryFailed atomic.Bool
+
+	// yt-dlp prints its ERROR lines to stderr; they are kept to classify
+	// the failure once it exits
+	var errorsMu sync.Mutex
+	var errorLines []string
+
+	// When resuming, yt-dlp may briefly report less progress than was already
+	// shown (e.g. before it picks up the .part file). Hold the previous values
+	// until it catches up so the progress bar doesn't jump back to 0%.
+	var floorMu sync.Mutex
+	var floorPercentage int
+	var floorBytes, floorTotal int64
+	if c.Resume {
+		progress, total := media.CurrentProgress()
+		floorPercentage = progress.Percentage
+		floorBytes = progress.DownloadedBytes
+		floorTotal = total
+	}
+
+	// yt-dlp reports no speed for many fragmented downloads, so estimate it
+	// from the downloaded bytes instead
+	var speedEstimator domain.SpeedEstimator
 
 	processOutput := func(reader io.Reader, name string) {
 		scanner := bufio.NewScanner(reader)
@@ -87,10 +166,65 @@ func (c *DownloadCommand) Execute(args any) error {
 			log.Printf("YTDLP %s: %s", name, line)
 			media.AppendLog(line)
 
+			if item, err := playlistParser.Parse(line); err == nil {
+				index, _ := strconv.Atoi(item["index"])
+				count, _ := strconv.Atoi(item["count"])
+				media.StartEntry(index, count)
+				continue
+			}
+
+			// With several videos yt-dlp reports errors per video and moves
+			// on to the next one
+			if strings.HasPrefix(line, "ERROR:") {
+				if name == "stderr" {
+					errorsMu.Lock()
+					errorLines = append(errorLines, line)
+					errorsMu.Unlock()
+				}
+				if media.CurrentEntry() > 0 {
+					entryFailed.Store(true)
+					media.SetEntryStatus(domain.Failed, "")
+				}
+				continue
+			}
+
+			if pp, err := postProcessParser.Parse(line); err == nil {
+				if processing.CompareAndSwap(false, true) {
+					media.SetStatus(domain.Processing)
+					media.AppendLog("[byto] Post-processing: " + pp["processor"])
+				}
+				media.SetEntryStatus(domain.Processing, "")
+			}
+
+			if skip, err := archiveParser.Parse(line); err == nil {
+				skippedAny.Store(true)
+				media.RecordSkip(skip["title"])
+				media.SetEntryStatus(domain.Skipped, skip["title"])
+				continue
+			}
+
+			if sub, err := subtitleParser.Parse(line); err == nil {
+				media.AppendLog(describeSubtitleEvent(sub))
+				continue
+			}
+
 			parsedData, err := p.Parse(line)
 			if err == nil {
-				// Update title if available
-				if title, ok := parsedData["title"]; ok && title != "" && title != "NA" && title != media.Title {
+				downloadedAny.Store(true)
+
+				// A new download started (e.g. the next playlist entry)
+				if processing.CompareAndSwap(true, false) {
+					media.SetStatus(domain.InProgress)
+				}
+
+				title := parsedData["title"]
+				if title == "NA" {
+					title = ""
+				}
+				// Playlist videos keep their title on their entry so the
+				// playlist title stays on the item
+				tracksEntries := media.CurrentEntry() > 0
+				if !tracksEntries && title != "" && title != media.Title {
 					media.SetTitle(title)
 				}
 
@@ -119,30 +253,163 @@ func (c *DownloadCommand) Execute(args any) error {
 						}
 					}
 				}
+				media.SetTransferStats(transferStats(parsedData, &speedEstimator, downloaded, total))
+
+				if tracksEntries {
+					media.UpdateEntryProgress(title, downloaded, total, percentage)
+					continue
+				}
+
+				floorMu.Lock()
+				if percentage < floorPercentage {
+					percentage = floorPercentage
+					if downloaded < floorBytes {
+						downloaded = floorBytes
+					}
+					if total < floorTotal {
+						total = floorTotal
+					}
+				} else {
+					floorPercentage = 0
+					floorBytes = 0
+				}
+				floorMu.Unlock()
+
 				media.UpdateProgress(downloaded, total, percentage)
 			}
 		}
 		if err := scanner.Err(); err != nil {
 			log.Printf("DownloadCommand: Error reading %s: %v", name, err)
 		}
+		// Keep draining so yt-dlp never blocks on a full pipe
+		io.Copy(io.Discard, reader)
 	}
 
-	// Read stdout and stderr concurrently
-	go processOutput(stdout, "stdout")
-	go processOutput(stderr, "stderr")
+	// Read stdout and stderr concurrently, and finish processing both
+	// before returning so late lines can't change the final status
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		processOutput(stdout, "stdout")
+	}()
+	go func() {
+		defer readers.Done()
+		processOutput(stderr, "stderr")
+	}()
+
+	err = cmd.Wait()
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	readers.Wait()
+
+	// Files finished before a pause or failure are kept too
+	if filesPath != "" {
+		media.AddOutputFiles(readOutputFilesList(filesPath)...)
+	}
 
-	if err := cmd.Wait(); err != nil {
+	if err != nil {
 		// Check if the error is due to context cancellation (pause)
+		if ctx.Err() == context.Canceled && context.Cause(ctx) == ErrRestart {
+			log.Printf("DownloadCommand: Download interrupted for a restart: %s", media.URL)
+			return context.Canceled
+		}
 		if ctx.Err() == context.Canceled {
 			log.Printf("DownloadCommand: Download paused for media: %s", media.URL)
+			media.FinishEntries(domain.Paused)
 			return context.Canceled
 		}
+		if entryFailed.Load() {
+			media.FinishEntries(domain.Completed)
+		} else {
+			media.FinishEntries(domain.Failed)
+		}
+		media.SetErrorKind(domain.ClassifyErrors(errorLines))
 		media.SetStatus(domain.Failed)
 		log.Printf("DownloadCommand: yt-dlp command failed for media %s: %v", media.URL, err)
 		return err
 	}
 
+	media.FinishEntries(domain.Completed)
+	if skippedAny.Load() && !downloadedAny.Load() {
+		media.SetStatus(domain.Skipped)
+		log.Printf("DownloadCommand: everything was already in the download archive for media: %s", media.URL)
+		return nil
+	}
+
 	media.SetStatus(domain.Completed)
 	log.Printf("DownloadCommand: yt-dlp command completed successfully for media: %s", media.URL)
 	return nil
 }
+
+// createOutputFilesList creates the empty file yt-dlp writes the output
+// paths to
+func createOutputFilesList() (string, error) {
+	f, err := os.CreateTemp("", "byto-files-*.txt")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
+// readOutputFilesList returns the paths listed in the file, one per line
+func readOutputFilesList(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("DownloadCommand: Error reading output files: %v", err)
+		return nil
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "NA" {
+			continue
+		}
+		files = append(files, line)
+	}
+	return files
+}
+
+// describeSubtitleEvent turns a parsed subtitle line into a short log entry
+func describeSubtitleEvent(event map[string]string) string {
+	switch event["event"] {
+	case "written":
+		return "[byto] Subtitles saved: " + event["path"]
+	case "embedded":
+		return "[byto] Subtitles embedded into: " + event["path"]
+	case "converted":
+		return "[byto] Converting subtitles"
+	default:
+		return "[byto] No subtitles available for the requested languages"
+	}
+}
+
+// transferStats returns the speed, ETA and elapsed time of a parsed progress
+// line, filling in the speed and ETA from the estimator when yt-dlp reports
+// NA
+func transferStats(parsedData map[string]string, estimator *domain.SpeedEstimator, downloaded, total int64) (float64, int, float64) {
+	estimated := estimator.Update(downloaded, time.Now())
+	elapsed, _ := strconv.ParseFloat(parsedData["elapsed"], 64)
+
+	speed, err := strconv.ParseFloat(parsedData["speed"], 64)
+	if err != nil {
+		speed = estimated
+	}
+
+	eta, err := strconv.Atoi(parsedData["eta"])
+	if err != nil {
+		eta = 0
+		fragIndex, _ := strconv.Atoi(parsedData["fragment_index"])
+		fragCount, _ := strconv.Atoi(parsedData["fragment_count"])
+		switch {
+		case total > downloaded && speed > 0:
+			eta = int(float64(total-downloaded) / speed)
+		case fragIndex > 0 && fragCount > fragIndex && elapsed > 0:
+			// Assume the remaining fragments take as long as the ones so far
+			eta = int(elapsed / float64(fragIndex) * float64(fragCount-fragIndex))
+		}
+	}
+	return speed, eta, elapsed
+}
@@ -0,0 +1,422 @@
+package command_test
+
+import (
+	"byto/internal/builder"
+	"byto/internal/command"
+	"byto/internal/domain"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// installFakeYtDlp puts a shell script named yt-dlp first on PATH so the
+// builder picks it up. Builders must be created after calling it.
+func installFakeYtDlp(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake yt-dlp script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "yt-dlp")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("failed to write fake yt-dlp: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// statusRecorder collects the statuses reported through OnStatusChange
+type statusRecorder struct {
+	mu       sync.Mutex
+	statuses []domain.DownloadStatus
+}
+
+func (r *statusRecorder) record(id string, status domain.DownloadStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status)
+}
+
+func (r *statusRecorder) contains(status domain.DownloadStatus) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLog(m *domain.Media, substr string) bool {
+	for _, l := range m.Progress.Logs {
+		if strings.Contains(l, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExecute_FakeYtDlp_ReportsProcessingThenCompleted(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[byto] Fake Video [downloaded] 50 [total] 100 [frag] NA [frags] NA"
+echo "[byto] Fake Video [downloaded] 100 [total] 100 [frag] NA [frags] NA"
+echo "[Merger] Merging formats into \"/tmp/Fake_Video.mp4\""
+echo "[Metadata] Adding metadata to \"/tmp/Fake_Video.mp4\""
+`)
+	rec := &statusRecorder{}
+	media := &domain.Media{ID: "1", URL: "http://example.com/video", OnStatusChange: rec.record}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if media.Status != domain.Completed {
+		t.Errorf("expected Completed, got %d", media.Status)
+	}
+	if !rec.contains(domain.Processing) {
+		t.Error("expected a Processing status while post-processing")
+	}
+	if !hasLog(media, "[byto] Post-processing: Merger") {
+		t.Errorf("expected post-processing log, got %v", media.Progress.Logs)
+	}
+	if media.Progress.Percentage != 100 || media.Title != "Fake Video" {
+		t.Errorf("unexpected progress %d%% / title %q", media.Progress.Percentage, media.Title)
+	}
+}
+
+func TestExecute_FakeYtDlp_SubtitleLogs(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[info] Writing video subtitles to: /tmp/clip.en.srt"
+`)
+	media := &domain.Media{ID: "1", URL: "http://example.com/video"}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasLog(media, "[byto] Subtitles saved: /tmp/clip.en.srt") {
+		t.Errorf("expected subtitle summary log, got %v", media.Progress.Logs)
+	}
+}
+
+func TestExecute_FakeYtDlp_PauseInterruptsGracefully(t *testing.T) {
+	installFakeYtDlp(t, `
+trap 'echo "fake yt-dlp interrupted"; exit 130' INT
+echo "[byto] Long Video [downloaded] 10 [total] 100 [frag] NA [frags] NA"
+while true; do sleep 0.05; done
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	media := &domain.Media{ID: "1", URL: "http://example.com/video", Ctx: ctx, CancelFunc: cancel}
+	cmd := &command.DownloadCommand{
+		Builder:     builder.NewYTDLPBuilder().URL(media.URL),
+		StopTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		media.Cancel()
+	}()
+
+	start := time.Now()
+	err := cmd.Execute(media)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Error("expected the process to exit on interrupt, not after the stop timeout")
+	}
+	if !hasLog(media, "fake yt-dlp interrupted") {
+		t.Errorf("expected yt-dlp to receive an interrupt, logs: %v", media.Progress.Logs)
+	}
+}
+
+func TestExecute_FakeYtDlp_PauseKillsAfterTimeout(t *testing.T) {
+	installFakeYtDlp(t, `
+trap '' INT
+while true; do sleep 0.05; done
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	media := &domain.Media{ID: "1", URL: "http://example.com/video", Ctx: ctx, CancelFunc: cancel}
+	cmd := &command.DownloadCommand{
+		Builder:     builder.NewYTDLPBuilder().URL(media.URL),
+		StopTimeout: 200 * time.Millisecond,
+	}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		media.Cancel()
+	}()
+
+	if err := cmd.Execute(media); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExecute_FakeYtDlp_ResumeKeepsProgress(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "$@" | grep -q -- "--continue" || echo "missing --continue"
+echo "[byto] Resumed [downloaded] 0 [total] NA [frag] NA [frags] NA"
+`)
+	media := &domain.Media{
+		ID:         "1",
+		URL:        "http://example.com/video",
+		TotalBytes: 1000,
+		Progress:   domain.DownloadProgress{Percentage: 40, DownloadedBytes: 400},
+	}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL), Resume: true}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasLog(media, "missing --continue") {
+		t.Error("expected resume to pass --continue")
+	}
+	if media.Progress.Percentage != 40 || media.Progress.DownloadedBytes != 400 {
+		t.Errorf("expected progress to stay at 40%%/400 bytes, got %d%%/%d", media.Progress.Percentage, media.Progress.DownloadedBytes)
+	}
+}
+
+func TestExecute_FakeYtDlp_AllArchivedReportsSkipped(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[download] First Video has already been recorded in the archive"
+echo "[download] Second Video has already been recorded in the archive"
+`)
+	media := &domain.Media{ID: "1", URL: "http://example.com/playlist", IsPlaylist: true}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if media.Status != domain.Skipped {
+		t.Errorf("expected Skipped, got %d", media.Status)
+	}
+	if media.Progress.Skipped != 2 {
+		t.Errorf("expected 2 skipped entries, got %d", media.Progress.Skipped)
+	}
+	if !hasLog(media, "[byto] Skipped (already in download archive): First Video") {
+		t.Errorf("expected skip log, got %v", media.Progress.Logs)
+	}
+}
+
+func TestExecute_FakeYtDlp_PartlyArchivedCompletes(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[download] Old Video has already been recorded in the archive"
+echo "[byto] New Video [downloaded] 100 [total] 100 [frag] NA [frags] NA"
+`)
+	media := &domain.Media{ID: "1", URL: "http://example.com/playlist", IsPlaylist: true}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if media.Status != domain.Completed {
+		t.Errorf("expected Completed, got %d", media.Status)
+	}
+	if media.Progress.Skipped != 1 {
+		t.Errorf("expected 1 skipped entry, got %d", media.Progress.Skipped)
+	}
+}
+
+func TestExecute_FakeYtDlp_TracksPlaylistEntries(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[download] Downloading item 1 of 3"
+echo "[byto] First [downloaded] 100 [total] 100 [frag] NA [frags] NA"
+echo "[download] Downloading item 2 of 3"
+echo "ERROR: [youtube] abc: Video unavailable"
+echo "[download] Downloading item 3 of 3"
+echo "[byto] Third [downloaded] 50 [total] 100 [frag] NA [frags] NA"
+exit 1
+`)
+	media := &domain.Media{ID: "1", URL: "http://example.com/playlist", Title: "My Playlist", IsPlaylist: true}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err == nil {
+		t.Fatal("expected an error when a playlist video fails")
+	}
+	if len(media.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", media.Entries)
+	}
+	expected := []domain.DownloadStatus{domain.Completed, domain.Failed, domain.Completed}
+	for i, status := range expected {
+		if media.Entries[i].Status != status {
+			t.Errorf("entry %d: expected status %d, got %d", i+1, status, media.Entries[i].Status)
+		}
+	}
+	if media.Entries[0].Title != "First" || media.Entries[2].Title != "Third" {
+		t.Errorf("unexpected entry titles: %v", media.Entries)
+	}
+	if media.Title != "My Playlist" {
+		t.Errorf("expected the playlist title to stay, got %q", media.Title)
+	}
+}
+
+func TestExecute_FakeYtDlp_ReportsSpeedAndETA(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[byto] Fast [downloaded] 500 [total] 1000 [frag] NA [frags] NA [speed] 250.5 [eta] 2 [elapsed] 2.0"
+echo "[byto] Fast [downloaded] 1000 [total] 1000 [frag] NA [frags] NA [speed] NA [eta] NA [elapsed] 4.0"
+`)
+	var mu sync.Mutex
+	var updates []domain.DownloadProgress
+	media := &domain.Media{
+		ID:  "1",
+		URL: "http://example.com/video",
+		OnProgress: func(id string, p domain.DownloadProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, p)
+		},
+	}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	found := false
+	for _, p := range updates {
+		if p.Speed == 250.5 && p.ETA == 2 && p.Elapsed == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a progress update with speed 250.5, ETA 2, elapsed 2, got %+v", updates)
+	}
+	if media.Progress.Speed != 0 || media.Progress.ETA != 0 {
+		t.Errorf("expected speed and ETA to be cleared once completed, got %f/%d", media.Progress.Speed, media.Progress.ETA)
+	}
+	if media.Progress.Elapsed != 4 {
+		t.Errorf("expected elapsed 4, got %f", media.Progress.Elapsed)
+	}
+}
+
+func TestExecute_FakeYtDlp_EstimatesFragmentETA(t *testing.T) {
+	installFakeYtDlp(t, `
+echo "[byto] HLS [downloaded] 100 [total] NA [frag] 5 [frags] 20 [speed] NA [eta] NA [elapsed] 10"
+`)
+	var mu sync.Mutex
+	var last domain.DownloadProgress
+	media := &domain.Media{
+		ID:  "1",
+		URL: "http://example.com/video",
+		OnProgress: func(id string, p domain.DownloadProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			if p.ETA > 0 {
+				last = p
+			}
+		},
+	}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 10s for 5 fragments, 15 fragments left
+	if last.ETA != 30 {
+		t.Errorf("expected an estimated ETA of 30s, got %d", last.ETA)
+	}
+}
+
+func TestExecute_FakeYtDlp_ClassifiesFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		errLine  string
+		expected domain.ErrorKind
+	}{
+		{name: "rate limited", errLine: "ERROR: [youtube] abc: HTTP Error 429: Too Many Requests", expected: domain.ErrorRateLimited},
+		{name: "network", errLine: "ERROR: [youtube] abc: Unable to download webpage: <urlopen error timed out>", expected: domain.ErrorNetwork},
+		{name: "unknown", errLine: "ERROR: something odd happened", expected: domain.ErrorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installFakeYtDlp(t, "echo \""+tt.errLine+"\" >&2\nexit 1\n")
+			media := &domain.Media{ID: "1", URL: "http://example.com/video"}
+			cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+			if err := cmd.Execute(media); err == nil {
+				t.Fatal("expected an error")
+			}
+			if media.ErrorKind != tt.expected {
+				t.Errorf("expected error kind %q, got %q", tt.expected, media.ErrorKind)
+			}
+		})
+	}
+}
+
+// printToFileArg is a fake yt-dlp snippet that sets $files to the file
+// passed to --print-to-file
+const printToFileArg = `
+files=""
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--print-to-file" ]; then files="$3"; fi
+	shift
+done
+`
+
+func TestExecute_FakeYtDlp_RecordsOutputFiles(t *testing.T) {
+	installFakeYtDlp(t, printToFileArg+`
+echo "[byto] Fake Video [downloaded] 100 [total] 100 [frag] NA [frags] NA"
+echo "[Merger] Merging formats into \"/tmp/Fake Video.mp4\""
+echo "/tmp/Fake Video.mp4" >> "$files"
+echo "/tmp/Second Video.mp4" >> "$files"
+`)
+	completedFiles := make(chan []string, 1)
+	media := &domain.Media{ID: "1", URL: "http://example.com/video", OutputFiles: []string{"/tmp/old.mp4"}}
+	media.OnStatusChange = func(id string, status domain.DownloadStatus) {
+		if status == domain.Completed {
+			completedFiles <- media.OutputFiles
+		}
+	}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL)}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"/tmp/Fake Video.mp4", "/tmp/Second Video.mp4"}
+	if !reflect.DeepEqual(media.OutputFiles, want) {
+		t.Errorf("expected output files %v, got %v", want, media.OutputFiles)
+	}
+	select {
+	case files := <-completedFiles:
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("expected the files to be recorded before completing, got %v", files)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a Completed status")
+	}
+}
+
+func TestExecute_FakeYtDlp_ResumeKeepsOutputFiles(t *testing.T) {
+	installFakeYtDlp(t, printToFileArg+`
+echo "/tmp/second.mp4" >> "$files"
+echo "/tmp/first.mp4" >> "$files"
+`)
+	media := &domain.Media{ID: "1", URL: "http://example.com/list", Status: domain.Paused, OutputFiles: []string{"/tmp/first.mp4"}}
+	cmd := &command.DownloadCommand{Builder: builder.NewYTDLPBuilder().URL(media.URL), Resume: true}
+
+	if err := cmd.Execute(media); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"/tmp/first.mp4", "/tmp/second.mp4"}
+	if !reflect.DeepEqual(media.OutputFiles, want) {
+		t.Errorf("expected output files %v, got %v", want, media.OutputFiles)
+	}
+}
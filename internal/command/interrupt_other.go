@@ -0,0 +1,11 @@
+//go:build !windows
+
+package command
+
+import "os"
+
+// interruptProcess asks the process to stop gracefully so yt-dlp can flush
+// and keep its partial files
+func interruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package command
+
+import "os"
+
+// interruptProcess kills the process on Windows, where console interrupts
+// cannot be delivered to a hidden child. yt-dlp's .part files are left in
+// place either way, so the download can still be continued.
+func interruptProcess(p *os.Process) error {
+	return p.Kill()
+}
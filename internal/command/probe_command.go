@@ -0,0 +1,82 @@
+package command
+
+import (
+	"bytes"
+	"byto/internal/builder"
+	"byto/internal/domain"
+	"byto/internal/parser"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTimeout bounds how long a metadata probe may run
+const DefaultProbeTimeout = 60 * time.Second
+
+// ProbeCommand runs yt-dlp in JSON-dump mode and fills the media's metadata
+// before it is downloaded.
+type ProbeCommand struct {
+	Builder *builder.YTDLPBuilder
+	// Timeout overrides DefaultProbeTimeout when non-zero
+	Timeout time.Duration
+	// Info holds the parsed metadata after a successful Execute
+	Info domain.MediaInfo
+}
+
+func (c *ProbeCommand) Execute(args any) error {
+	if c.Builder == nil {
+		return fmt.Errorf("YTDLPBuilder is nil")
+	}
+
+	media, ok := args.(*domain.Media)
+	if !ok {
+		return fmt.Errorf("invalid arguments, expected *domain.Media")
+	}
+	log.Printf("ProbeCommand: Probing media: %s", media.URL)
+
+	c.Builder.ProbeJSON()
+	ucmd := c.Builder.Build()
+	ytdlpPath := c.Builder.GetYtDlpPath()
+
+	parent := media.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ytdlpPath, ucmd...)
+	HideWindow(cmd) // Hide console window on Windows
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg := strings.TrimSpace(ensureUTF8(stderr.String()))
+		if msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	info, err := parser.YTDLPInfoParser{}.ParseInfo([]byte(ensureUTF8(stdout.String())))
+	if err != nil {
+		return fmt.Errorf("failed to parse yt-dlp output: %w", err)
+	}
+
+	c.Info = info
+	media.ApplyInfo(info)
+	log.Printf("ProbeCommand: Probed %s: title=%q, playlist=%v", media.URL, info.Title, info.IsPlaylist)
+	return nil
+}
@@ -0,0 +1,52 @@
+package command_test
+
+import (
+	"byto/internal/builder"
+	"byto/internal/command"
+	"byto/internal/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProbe_NilBuilder(t *testing.T) {
+	cmd := &command.ProbeCommand{Builder: nil}
+	if err := cmd.Execute(&domain.Media{URL: "http://example.com/video"}); err == nil {
+		t.Fatal("expected error when builder is nil, got nil")
+	}
+}
+
+func TestProbe_InvalidArgsType(t *testing.T) {
+	cmd := &command.ProbeCommand{Builder: builder.NewYTDLPBuilder()}
+	if err := cmd.Execute("invalid args"); err == nil {
+		t.Fatal("expected error when args is a string")
+	}
+}
+
+func TestProbe_NoURL_ReturnsError(t *testing.T) {
+	cmd := &command.ProbeCommand{Builder: builder.NewYTDLPBuilder()}
+	media := &domain.Media{Title: "Pending..."}
+	if err := cmd.Execute(media); err == nil {
+		t.Error("expected error when builder has no URL")
+	}
+	if media.Title != "Pending..." {
+		t.Errorf("title should be untouched on failure, got %q", media.Title)
+	}
+}
+
+func TestProbe_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cmd := &command.ProbeCommand{
+		Builder: builder.NewYTDLPBuilder().URL("http://example.com/video"),
+		Timeout: time.Second,
+	}
+	if err := cmd.Execute(&domain.Media{URL: "http://example.com/video", Ctx: ctx}); err == nil {
+		t.Error("expected error with already-cancelled context")
+	}
+}
+
+func TestProbeCommand_ImplementsCommandInterface(t *testing.T) {
+	var _ command.Command = (*command.ProbeCommand)(nil)
+}
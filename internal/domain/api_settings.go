@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultAPIPort is the port of the local control API when none is set
+const DefaultAPIPort = 7979
+
+// APISettings configures the optional local HTTP control API. It listens on
+// 127.0.0.1 only and every request must carry Token.
+type APISettings struct {
+	Enabled bool   `json:"enabled"`
+	Port    int    `json:"port"`
+	Token   string `json:"token"`
+}
+
+func DefaultAPISettings() APISettings {
+	return APISettings{
+		Port: DefaultAPIPort,
+	}
+}
+
+func (s APISettings) Validate() error {
+	if s.Port < 1 || s.Port > 65535 {
+		return fmt.Errorf("API port must be between 1 and 65535, got %d", s.Port)
+	}
+	if s.Enabled && s.Token == "" {
+		return errors.New("API token must not be empty")
+	}
+	return nil
+}
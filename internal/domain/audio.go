@@ -0,0 +1,38 @@
+package domain
+
+import "fmt"
+
+type AudioCodec string
+
+const (
+	AudioOriginal AudioCodec = "" // keep what the site serves
+	AudioMP3      AudioCodec = "mp3"
+	AudioM4A      AudioCodec = "m4a"
+	AudioOpus     AudioCodec = "opus"
+	AudioFLAC     AudioCodec = "flac"
+	AudioWAV      AudioCodec = "wav"
+)
+
+// AudioProfile is the target format for audio-only downloads. Converting
+// needs ffmpeg.
+type AudioProfile struct {
+	Codec   AudioCodec `json:"codec"`
+	Bitrate int        `json:"bitrate"` // kbit/s, 0 for best quality; ignored for lossless codecs
+}
+
+// IsLossless reports whether the codec ignores the bitrate
+func (a AudioProfile) IsLossless() bool {
+	return a.Codec == AudioFLAC || a.Codec == AudioWAV
+}
+
+func (a AudioProfile) Validate() error {
+	switch a.Codec {
+	case AudioOriginal, AudioMP3, AudioM4A, AudioOpus, AudioFLAC, AudioWAV:
+	default:
+		return fmt.Errorf("unsupported audio codec: %q", a.Codec)
+	}
+	if a.Bitrate < 0 || a.Bitrate > 512 {
+		return fmt.Errorf("invalid audio bitrate: %d kbit/s", a.Bitrate)
+	}
+	return nil
+}
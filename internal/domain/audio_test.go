@@ -0,0 +1,80 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestAudioProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     domain.AudioProfile
+		expectError bool
+	}{
+		{name: "original", profile: domain.AudioProfile{}},
+		{name: "mp3 192", profile: domain.AudioProfile{Codec: domain.AudioMP3, Bitrate: 192}},
+		{name: "flac", profile: domain.AudioProfile{Codec: domain.AudioFLAC}},
+		{name: "max bitrate", profile: domain.AudioProfile{Codec: domain.AudioOpus, Bitrate: 512}},
+		{name: "unknown codec", profile: domain.AudioProfile{Codec: "aac"}, expectError: true},
+		{name: "negative bitrate", profile: domain.AudioProfile{Codec: domain.AudioMP3, Bitrate: -1}, expectError: true},
+		{name: "bitrate too high", profile: domain.AudioProfile{Codec: domain.AudioM4A, Bitrate: 1000}, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAudioProfile_IsLossless(t *testing.T) {
+	lossless := map[domain.AudioCodec]bool{
+		domain.AudioOriginal: false,
+		domain.AudioMP3:      false,
+		domain.AudioM4A:      false,
+		domain.AudioOpus:     false,
+		domain.AudioFLAC:     true,
+		domain.AudioWAV:      true,
+	}
+	for codec, expected := range lossless {
+		if got := (domain.AudioProfile{Codec: codec}).IsLossless(); got != expected {
+			t.Errorf("IsLossless(%q) = %v, want %v", codec, got, expected)
+		}
+	}
+}
+
+func TestMedia_NeedsFfmpeg(t *testing.T) {
+	tests := []struct {
+		name     string
+		media    *domain.Media
+		expected bool
+	}{
+		{name: "plain video", media: &domain.Media{}, expected: false},
+		{name: "plain audio", media: &domain.Media{OnlyAudio: true}, expected: false},
+		{name: "audio conversion", media: &domain.Media{OnlyAudio: true, Audio: domain.AudioProfile{Codec: domain.AudioMP3}}, expected: true},
+		{name: "profile ignored for video", media: &domain.Media{Audio: domain.AudioProfile{Codec: domain.AudioMP3}}, expected: false},
+		{name: "embedding", media: &domain.Media{Embed: domain.EmbedOptions{Chapters: true}}, expected: true},
+		{name: "subtitles", media: &domain.Media{Subtitles: domain.SubtitleOptions{Enabled: true}}, expected: true},
+		{name: "subtitles off", media: &domain.Media{Subtitles: domain.SubtitleOptions{Languages: []string{"en"}}}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.media.NeedsFfmpeg(); got != tt.expected {
+				t.Errorf("NeedsFfmpeg() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMediaDefaults_UpdateAudio(t *testing.T) {
+	m := &domain.MediaDefaults{}
+	m.UpdateAudio(domain.AudioProfile{Codec: domain.AudioM4A, Bitrate: 256})
+	if m.Audio.Codec != domain.AudioM4A || m.Audio.Bitrate != 256 {
+		t.Errorf("unexpected audio defaults: %+v", m.Audio)
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CookieBrowsers are the browsers yt-dlp can read cookies from
+var CookieBrowsers = []string{"brave", "chrome", "chromium", "edge", "firefox", "opera", "safari", "vivaldi", "whale"}
+
+// AuthProfile gives yt-dlp the cookies of a logged-in session, either from
+// a Netscape-format cookie file or read straight from a browser. Sites lists
+// the domains the profile is used for when an item has no profile of its own.
+type AuthProfile struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Sites []string `json:"sites"`
+	// CookieFile is the path of a Netscape cookie file
+	CookieFile string `json:"cookie_file,omitempty"`
+	// Browser is one of CookieBrowsers; BrowserProfile optionally picks a
+	// profile of that browser by name or path
+	Browser        string `json:"browser,omitempty"`
+	BrowserProfile string `json:"browser_profile,omitempty"`
+}
+
+func (p AuthProfile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("profile name must not be empty")
+	}
+	switch {
+	case p.CookieFile != "" && p.Browser != "":
+		return errors.New("a profile uses either a cookie file or a browser, not both")
+	case p.CookieFile == "" && p.Browser == "":
+		return errors.New("a profile needs a cookie file or a browser")
+	case p.Browser != "" && !isCookieBrowser(p.Browser):
+		return fmt.Errorf("unsupported browser %q, expected one of %s", p.Browser, strings.Join(CookieBrowsers, ", "))
+	}
+	return nil
+}
+
+func isCookieBrowser(browser string) bool {
+	for _, b := range CookieBrowsers {
+		if b == browser {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesURL reports whether the URL's host matches one of the profile's
+// sites, see HostMatches
+func (p AuthProfile) MatchesURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, site := range p.Sites {
+		if HostMatches(u.Hostname(), site) {
+			return true
+		}
+	}
+	return false
+}
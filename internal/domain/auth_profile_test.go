@@ -0,0 +1,50 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestAuthProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile domain.AuthProfile
+		wantErr bool
+	}{
+		{"cookie file", domain.AuthProfile{Name: "a", CookieFile: "/c.txt"}, false},
+		{"browser", domain.AuthProfile{Name: "a", Browser: "firefox", BrowserProfile: "default"}, false},
+		{"no name", domain.AuthProfile{CookieFile: "/c.txt"}, true},
+		{"no source", domain.AuthProfile{Name: "a"}, true},
+		{"both sources", domain.AuthProfile{Name: "a", CookieFile: "/c.txt", Browser: "chrome"}, true},
+		{"unknown browser", domain.AuthProfile{Name: "a", Browser: "netscape"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthProfile_MatchesURL(t *testing.T) {
+	profile := domain.AuthProfile{Sites: []string{"youtube.com", " .Patreon.com "}}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://youtube.com/watch?v=1", true},
+		{"https://www.youtube.com/watch?v=1", true},
+		{"https://music.youtube.com/watch?v=1", true},
+		{"https://www.patreon.com/posts/1", true},
+		{"https://notyoutube.com/watch?v=1", false},
+		{"https://vimeo.com/1", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := profile.MatchesURL(tt.url); got != tt.want {
+			t.Errorf("MatchesURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
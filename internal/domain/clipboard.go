@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultClipboardSites are the sites whose copied links the clipboard
+// watcher picks up when no sites are configured
+var DefaultClipboardSites = []string{
+	"youtube.com", "youtu.be", "vimeo.com", "dailymotion.com", "twitch.tv",
+	"soundcloud.com", "bandcamp.com", "tiktok.com", "instagram.com",
+	"facebook.com", "x.com", "twitter.com", "reddit.com", "bilibili.com",
+}
+
+// ClipboardSettings configures the optional clipboard watcher, which picks
+// up media links as they are copied
+type ClipboardSettings struct {
+	Enabled bool `json:"enabled"`
+	// AutoAdd queues copied links right away with the media defaults,
+	// instead of offering them to the user
+	AutoAdd bool `json:"auto_add"`
+	// Sites lists the domains whose links are picked up, subdomains
+	// included; empty uses DefaultClipboardSites
+	Sites []string `json:"sites,omitempty"`
+}
+
+func (s ClipboardSettings) Validate() error {
+	for _, site := range s.Sites {
+		if strings.TrimSpace(site) == "" {
+			return errors.New("clipboard site must not be empty")
+		}
+	}
+	return nil
+}
+
+// WatchedSites returns the sites the watcher picks up links of
+func (s ClipboardSettings) WatchedSites() []string {
+	if len(s.Sites) == 0 {
+		return DefaultClipboardSites
+	}
+	return s.Sites
+}
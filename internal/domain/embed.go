@@ -0,0 +1,14 @@
+package domain
+
+// EmbedOptions controls what yt-dlp writes into the output file besides the
+// media streams. Embedding needs ffmpeg.
+type EmbedOptions struct {
+	Thumbnail bool `json:"thumbnail"`
+	Metadata  bool `json:"metadata"`
+	Chapters  bool `json:"chapters"`
+}
+
+// Any reports whether at least one embed option is enabled
+func (e EmbedOptions) Any() bool {
+	return e.Thumbnail || e.Metadata || e.Chapters
+}
@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestEmbedOptions_Any(t *testing.T) {
+	tests := []struct {
+		name     string
+		embed    domain.EmbedOptions
+		expected bool
+	}{
+		{name: "none", embed: domain.EmbedOptions{}, expected: false},
+		{name: "thumbnail", embed: domain.EmbedOptions{Thumbnail: true}, expected: true},
+		{name: "metadata", embed: domain.EmbedOptions{Metadata: true}, expected: true},
+		{name: "chapters", embed: domain.EmbedOptions{Chapters: true}, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.embed.Any(); got != tt.expected {
+				t.Errorf("Any() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMediaDefaults_UpdateEmbed(t *testing.T) {
+	m := &domain.MediaDefaults{}
+	m.UpdateEmbed(domain.EmbedOptions{Thumbnail: true, Chapters: true})
+	if !m.Embed.Thumbnail || m.Embed.Metadata || !m.Embed.Chapters {
+		t.Errorf("unexpected embed defaults: %+v", m.Embed)
+	}
+}
+
+func TestDownloadStatus_IsRunning(t *testing.T) {
+	running := map[domain.DownloadStatus]bool{
+		domain.Pending:    false,
+		domain.InProgress: true,
+		domain.Completed:  false,
+		domain.Failed:     false,
+		domain.Paused:     false,
+		domain.Processing: true,
+	}
+	for status, expected := range running {
+		if got := status.IsRunning(); got != expected {
+			t.Errorf("DownloadStatus(%d).IsRunning() = %v, want %v", status, got, expected)
+		}
+	}
+}
+
+func TestDownloadStatus_IsFinished(t *testing.T) {
+	finished := map[domain.DownloadStatus]bool{
+		domain.Pending:    false,
+		domain.InProgress: false,
+		domain.Completed:  true,
+		domain.Failed:     true,
+		domain.Paused:     false,
+		domain.Processing: false,
+		domain.Skipped:    true,
+		domain.Scheduled:  false,
+	}
+	for status, expected := range finished {
+		if got := status.IsFinished(); got != expected {
+			t.Errorf("DownloadStatus(%d).IsFinished() = %v, want %v", status, got, expected)
+		}
+	}
+}
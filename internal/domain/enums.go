@@ -1,5 +1,7 @@
 package domain
 
+import "strconv"
+
 type VideoQuality int
 
 const (
@@ -11,6 +13,55 @@ const (
 	Quality2160p
 )
 
+// ParseVideoQuality converts a quality label such as "720p". Unknown labels
+// default to 1080p.
+func ParseVideoQuality(quality string) VideoQuality {
+	switch quality {
+	case "360p":
+		return Quality360p
+	case "480p":
+		return Quality480p
+	case "720p":
+		return Quality720p
+	case "1080p":
+		return Quality1080p
+	case "1440p":
+		return Quality1440p
+	case "2160p":
+		return Quality2160p
+	default:
+		return Quality1080p
+	}
+}
+
+// String returns the quality label, e.g. "720p"
+func (q VideoQuality) String() string {
+	if h := q.Height(); h > 0 {
+		return strconv.Itoa(h) + "p"
+	}
+	return "unknown"
+}
+
+// Height returns the maximum video height for the quality, or 0 if unknown
+func (q VideoQuality) Height() int {
+	switch q {
+	case Quality360p:
+		return 360
+	case Quality480p:
+		return 480
+	case Quality720p:
+		return 720
+	case Quality1080p:
+		return 1080
+	case Quality1440p:
+		return 1440
+	case Quality2160p:
+		return 2160
+	default:
+		return 0
+	}
+}
+
 type DownloadStatus int
 
 const (
@@ -19,4 +70,47 @@ const (
 	Completed
 	Failed
 	Paused
+	// Processing means the download finished and yt-dlp is merging,
+	// converting or embedding into the output file
+	Processing
+	// Skipped means every video of the item was already recorded in the
+	// download archive, so nothing was downloaded
+	Skipped
+	// Scheduled means the item waits for its start time or for the download
+	// window to open
+	Scheduled
 )
+
+func (s DownloadStatus) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case InProgress:
+		return "downloading"
+	case Completed:
+		return "completed"
+	case Failed:
+		return "failed"
+	case Paused:
+		return "paused"
+	case Processing:
+		return "processing"
+	case Skipped:
+		return "skipped"
+	case Scheduled:
+		return "scheduled"
+	default:
+		return "unknown"
+	}
+}
+
+// IsRunning reports whether a download process is active for the status
+func (s DownloadStatus) IsRunning() bool {
+	return s == InProgress || s == Processing
+}
+
+// IsFinished reports whether the download came to an end, whether it
+// produced files or not
+func (s DownloadStatus) IsFinished() bool {
+	return s == Completed || s == Failed || s == Skipped
+}
@@ -0,0 +1,118 @@
+package domain
+
+import "strings"
+
+// ErrorKind classifies why a download failed
+type ErrorKind string
+
+const (
+	ErrorNone           ErrorKind = ""
+	ErrorUnknown        ErrorKind = "unknown"
+	ErrorNetwork        ErrorKind = "network"
+	ErrorRateLimited    ErrorKind = "rate_limited"
+	ErrorFfmpegMissing  ErrorKind = "ffmpeg_missing"
+	ErrorGeoBlocked     ErrorKind = "geo_blocked"
+	ErrorUnavailable    ErrorKind = "unavailable" // private or removed
+	ErrorLoginRequired  ErrorKind = "login_required"
+	ErrorUnsupportedURL ErrorKind = "unsupported_url"
+	ErrorDiskFull       ErrorKind = "disk_full"
+)
+
+// IsKnown reports whether the kind is one of the kinds above, other than
+// ErrorNone
+func (k ErrorKind) IsKnown() bool {
+	switch k {
+	case ErrorUnknown, ErrorNetwork, ErrorRateLimited, ErrorFfmpegMissing, ErrorGeoBlocked,
+		ErrorUnavailable, ErrorLoginRequired, ErrorUnsupportedURL, ErrorDiskFull:
+		return true
+	}
+	return false
+}
+
+// errorPatterns maps parts of yt-dlp error messages to their kind. They are
+// checked in order, so more specific kinds come first.
+var errorPatterns = []struct {
+	kind     ErrorKind
+	patterns []string
+}{
+	{ErrorFfmpegMissing, []string{"ffmpeg not found", "ffprobe and ffmpeg not found", "ffmpeg is not installed", "ffmpeg could not be found"}},
+	{ErrorDiskFull, []string{"no space left on device", "errno 28", "not enough space on the disk"}},
+	{ErrorRateLimited, []string{"http error 429", "too many requests", "rate-limited", "rate limit"}},
+	{ErrorGeoBlocked, []string{"available in your country", "not available from your location", "geo restriction", "geo-restricted", "blocked it in your country"}},
+	{ErrorUnavailable, []string{"private video", "video unavailable", "has been removed", "no longer available", "account associated with this video has been terminated", "http error 404", "video does not exist", "playlist does not exist", "channel does not exist"}},
+	// YouTube's bot check is spelled with a typographic or a plain apostrophe
+	{ErrorLoginRequired, []string{"sign in to confirm your age", "sign in to confirm you’re not a bot", "sign in to confirm you're not a bot", "age-restricted", "inappropriate for some users", "login required", "requires authentication", "members-only", "join this channel", "use --cookies", "http error 401"}},
+	{ErrorUnsupportedURL, []string{"unsupported url", "is not a valid url"}},
+	{ErrorNetwork, []string{
+		"unable to download webpage",
+		"unable to download json metadata",
+		"timed out",
+		"connection reset",
+		"connection refused",
+		"connection aborted",
+		"network is unreachable",
+		"temporary failure in name resolution",
+		"getaddrinfo failed",
+		"incompleteread",
+		"http error 500",
+		"http error 502",
+		"http error 503",
+		"http error 504",
+	}},
+}
+
+// ClassifyError returns the kind of a yt-dlp error message, or ErrorUnknown
+// when no known pattern matches
+func ClassifyError(message string) ErrorKind {
+	message = strings.ToLower(message)
+	for _, p := range errorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(message, pattern) {
+				return p.kind
+			}
+		}
+	}
+	return ErrorUnknown
+}
+
+// ClassifyErrors returns the kind of the most specific message among the
+// errors of one download
+func ClassifyErrors(messages []string) ErrorKind {
+	best := ErrorUnknown
+	bestRank := len(errorPatterns)
+	for _, message := range messages {
+		kind := ClassifyError(message)
+		for rank, p := range errorPatterns {
+			if p.kind == kind && rank < bestRank {
+				best, bestRank = kind, rank
+			}
+		}
+	}
+	return best
+}
+
+// Hint returns what the user can do about a failure of this kind
+func (k ErrorKind) Hint() string {
+	switch k {
+	case ErrorNetwork:
+		return "The connection failed. Check your internet connection and try again."
+	case ErrorRateLimited:
+		return "The site is limiting requests. Wait a while, or lower the number of parallel downloads."
+	case ErrorFfmpegMissing:
+		return "ffmpeg is required for this download. Install it from the settings and try again."
+	case ErrorGeoBlocked:
+		return "This video is not available in your region."
+	case ErrorUnavailable:
+		return "This video is private or has been removed."
+	case ErrorLoginRequired:
+		return "This video requires signing in (age-restricted or members-only content). Attach an authentication profile with cookies for this site and try again."
+	case ErrorUnsupportedURL:
+		return "This URL is not supported. Check that it links to a video or playlist."
+	case ErrorDiskFull:
+		return "The disk is full. Free up space or choose another download folder."
+	case ErrorNone:
+		return ""
+	default:
+		return "The download failed. Check the logs for details."
+	}
+}
@@ -0,0 +1,85 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected domain.ErrorKind
+	}{
+		{name: "geo blocked", message: "ERROR: [youtube] abc: The uploader has not made this video available in your country", expected: domain.ErrorGeoBlocked},
+		{name: "geo restriction", message: "ERROR: [bbc] abc: This video is not available from your location due to geo restriction", expected: domain.ErrorGeoBlocked},
+		{name: "private video", message: "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", expected: domain.ErrorUnavailable},
+		{name: "removed video", message: "ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", expected: domain.ErrorUnavailable},
+		{name: "age restricted", message: "ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.", expected: domain.ErrorLoginRequired},
+		{name: "bot check", message: "ERROR: [youtube] abc: Sign in to confirm you’re not a bot. Use --cookies-from-browser or --cookies for the authentication.", expected: domain.ErrorLoginRequired},
+		{name: "bot check plain apostrophe", message: "ERROR: [youtube] abc: Sign in to confirm you're not a bot. This helps protect our community.", expected: domain.ErrorLoginRequired},
+		{name: "missing playlist", message: "ERROR: [youtube:tab] PLabc: The playlist does not exist.", expected: domain.ErrorUnavailable},
+		{name: "missing output folder", message: "ERROR: unable to open for writing: [Errno 2] The directory /downloads/x does not exist", expected: domain.ErrorUnknown},
+		{name: "other sign in prompt", message: "ERROR: [example] abc: Sign in to confirm your email address before downloading", expected: domain.ErrorUnknown},
+		{name: "members only", message: "ERROR: [youtube] abc: Join this channel to get access to members-only content like this video", expected: domain.ErrorLoginRequired},
+		{name: "rate limited", message: "ERROR: [youtube] abc: HTTP Error 429: Too Many Requests", expected: domain.ErrorRateLimited},
+		{name: "unsupported url", message: "ERROR: Unsupported URL: https://example.com/page", expected: domain.ErrorUnsupportedURL},
+		{name: "ffmpeg missing", message: "ERROR: Postprocessing: ffprobe and ffmpeg not found. Please install or provide the path using --ffmpeg-location", expected: domain.ErrorFfmpegMissing},
+		{name: "disk full", message: "ERROR: unable to write data: [Errno 28] No space left on device", expected: domain.ErrorDiskFull},
+		{name: "network", message: "ERROR: [youtube] abc: Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>", expected: domain.ErrorNetwork},
+		{name: "server error", message: "ERROR: unable to download video data: HTTP Error 503: Service Unavailable", expected: domain.ErrorNetwork},
+		{name: "unknown", message: "ERROR: something odd happened", expected: domain.ErrorUnknown},
+		{name: "empty", message: "", expected: domain.ErrorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domain.ClassifyError(tt.message); got != tt.expected {
+				t.Errorf("ClassifyError() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClassifyErrors_PicksMostSpecific(t *testing.T) {
+	messages := []string{
+		"ERROR: [youtube] abc: Unable to download webpage: timed out",
+		"ERROR: [youtube] abc: HTTP Error 429: Too Many Requests",
+	}
+	if got := domain.ClassifyErrors(messages); got != domain.ErrorRateLimited {
+		t.Errorf("expected rate_limited, got %q", got)
+	}
+}
+
+func TestClassifyErrors_NoMessages(t *testing.T) {
+	if got := domain.ClassifyErrors(nil); got != domain.ErrorUnknown {
+		t.Errorf("expected unknown, got %q", got)
+	}
+}
+
+func TestErrorKind_Hint(t *testing.T) {
+	kinds := []domain.ErrorKind{
+		domain.ErrorUnknown,
+		domain.ErrorNetwork,
+		domain.ErrorRateLimited,
+		domain.ErrorFfmpegMissing,
+		domain.ErrorGeoBlocked,
+		domain.ErrorUnavailable,
+		domain.ErrorLoginRequired,
+		domain.ErrorUnsupportedURL,
+		domain.ErrorDiskFull,
+	}
+	seen := map[string]bool{}
+	for _, k := range kinds {
+		hint := k.Hint()
+		if hint == "" {
+			t.Errorf("expected a hint for %q", k)
+		}
+		if seen[hint] {
+			t.Errorf("hint for %q is not specific: %q", k, hint)
+		}
+		seen[hint] = true
+	}
+	if domain.ErrorNone.Hint() != "" {
+		t.Error("expected no hint without an error")
+	}
+}
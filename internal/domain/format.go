@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type VideoCodec string
+
+const (
+	CodecAny  VideoCodec = ""
+	CodecAV1  VideoCodec = "av1"
+	CodecVP9  VideoCodec = "vp9"
+	CodecH265 VideoCodec = "h265"
+	CodecH264 VideoCodec = "h264"
+)
+
+type Container string
+
+const (
+	ContainerAny  Container = ""
+	ContainerMP4  Container = "mp4"
+	ContainerWebM Container = "webm"
+	ContainerMKV  Container = "mkv"
+)
+
+var formatIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-+/]+$`)
+
+// FormatSelection picks the format to download. An explicit FormatID (as
+// listed by a probe) wins; otherwise the remaining fields are preferences
+// used to sort the available formats.
+type FormatSelection struct {
+	FormatID  string     `json:"format_id,omitempty"`
+	Codec     VideoCodec `json:"codec,omitempty"`
+	Container Container  `json:"container,omitempty"`
+	MaxHeight int        `json:"max_height,omitempty"`
+	FPS       int        `json:"fps,omitempty"`
+	HDR       bool       `json:"hdr,omitempty"`
+	// VCodec and ACodec are the codecs of the format picked by FormatID, as
+	// listed by the probe, so a video-only format can be merged with audio
+	VCodec string `json:"vcodec,omitempty"`
+	ACodec string `json:"acodec,omitempty"`
+}
+
+// IsZero reports whether no format preference was set
+func (f FormatSelection) IsZero() bool {
+	return f == FormatSelection{}
+}
+
+// MissingAudio reports whether the format picked by FormatID has no audio
+// of its own. Selections without the probed codecs, e.g. saved before they
+// were kept, are taken to be video-only.
+func (f FormatSelection) MissingAudio() bool {
+	info := FormatInfo{VCodec: f.VCodec, ACodec: f.ACodec}
+	if info.VCodec == "" && info.ACodec == "" {
+		return true
+	}
+	return !info.HasAudio()
+}
+
+func (f FormatSelection) Validate() error {
+	if f.FormatID != "" && !formatIDPattern.MatchString(f.FormatID) {
+		return fmt.Errorf("invalid format id: %q", f.FormatID)
+	}
+	switch f.Codec {
+	case CodecAny, CodecAV1, CodecVP9, CodecH265, CodecH264:
+	default:
+		return fmt.Errorf("unsupported codec: %q", f.Codec)
+	}
+	switch f.Container {
+	case ContainerAny, ContainerMP4, ContainerWebM, ContainerMKV:
+	default:
+		return fmt.Errorf("unsupported container: %q", f.Container)
+	}
+	if f.MaxHeight < 0 {
+		return fmt.Errorf("invalid max height: %d", f.MaxHeight)
+	}
+	if f.FPS < 0 {
+		return fmt.Errorf("invalid fps: %d", f.FPS)
+	}
+	return nil
+}
+
+// FormatInfo describes one format offered by the site, as reported by a probe
+type FormatInfo struct {
+	FormatID     string  `json:"format_id"`
+	Ext          string  `json:"ext"`
+	Resolution   string  `json:"resolution"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+	FPS          float64 `json:"fps"`
+	VCodec       string  `json:"vcodec"`
+	ACodec       string  `json:"acodec"`
+	DynamicRange string  `json:"dynamic_range"`
+	Filesize     int64   `json:"filesize"`
+	Bitrate      float64 `json:"bitrate"` // kbit/s
+	Note         string  `json:"note"`
+}
+
+func (f FormatInfo) HasVideo() bool {
+	return f.VCodec != "" && f.VCodec != "none"
+}
+
+func (f FormatInfo) HasAudio() bool {
+	return f.ACodec != "" && f.ACodec != "none"
+}
@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestFormatSelection_IsZero(t *testing.T) {
+	if !(domain.FormatSelection{}).IsZero() {
+		t.Error("expected zero value to report IsZero")
+	}
+	if (domain.FormatSelection{FPS: 30}).IsZero() {
+		t.Error("expected selection with fps to not be zero")
+	}
+}
+
+func TestFormatSelection_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		selection   domain.FormatSelection
+		expectError bool
+	}{
+		{name: "zero value", selection: domain.FormatSelection{}},
+		{name: "numeric id", selection: domain.FormatSelection{FormatID: "137"}},
+		{name: "merged id", selection: domain.FormatSelection{FormatID: "137+140"}},
+		{name: "named id", selection: domain.FormatSelection{FormatID: "hls-1080p"}},
+		{name: "id with spaces", selection: domain.FormatSelection{FormatID: "137 140"}, expectError: true},
+		{name: "id with shell chars", selection: domain.FormatSelection{FormatID: "137;ls"}, expectError: true},
+		{name: "all codecs", selection: domain.FormatSelection{Codec: domain.CodecH265}},
+		{name: "unknown codec", selection: domain.FormatSelection{Codec: "mpeg2"}, expectError: true},
+		{name: "unknown container", selection: domain.FormatSelection{Container: "avi"}, expectError: true},
+		{name: "negative height", selection: domain.FormatSelection{MaxHeight: -1}, expectError: true},
+		{name: "negative fps", selection: domain.FormatSelection{FPS: -30}, expectError: true},
+		{name: "full preference", selection: domain.FormatSelection{Codec: domain.CodecAV1, Container: domain.ContainerMKV, MaxHeight: 2160, FPS: 60, HDR: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selection.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatInfo_HasVideoAndAudio(t *testing.T) {
+	tests := []struct {
+		name        string
+		info        domain.FormatInfo
+		expectVideo bool
+		expectAudio bool
+	}{
+		{name: "muxed", info: domain.FormatInfo{VCodec: "avc1.64001F", ACodec: "mp4a.40.2"}, expectVideo: true, expectAudio: true},
+		{name: "video only", info: domain.FormatInfo{VCodec: "vp9", ACodec: "none"}, expectVideo: true},
+		{name: "audio only", info: domain.FormatInfo{VCodec: "none", ACodec: "opus"}, expectAudio: true},
+		{name: "unknown", info: domain.FormatInfo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.HasVideo(); got != tt.expectVideo {
+				t.Errorf("HasVideo() = %v, want %v", got, tt.expectVideo)
+			}
+			if got := tt.info.HasAudio(); got != tt.expectAudio {
+				t.Errorf("HasAudio() = %v, want %v", got, tt.expectAudio)
+			}
+		})
+	}
+}
+
+func TestVideoQuality_Height(t *testing.T) {
+	expected := map[domain.VideoQuality]int{
+		domain.Quality360p:      360,
+		domain.Quality480p:      480,
+		domain.Quality720p:      720,
+		domain.Quality1080p:     1080,
+		domain.Quality1440p:     1440,
+		domain.Quality2160p:     2160,
+		domain.VideoQuality(99): 0,
+	}
+	for q, h := range expected {
+		if got := q.Height(); got != h {
+			t.Errorf("VideoQuality(%d).Height() = %d, want %d", q, got, h)
+		}
+	}
+}
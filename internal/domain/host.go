@@ -0,0 +1,19 @@
+package domain
+
+import "strings"
+
+// HostMatches reports whether the host is the site's domain or a subdomain
+// of it, so "youtube.com" also covers "www.youtube.com". Both are compared
+// ignoring case, and a leading dot on the site is ignored.
+func HostMatches(host, site string) bool {
+	host = strings.ToLower(host)
+	site = normalizeSite(site)
+	if host == "" || site == "" {
+		return false
+	}
+	return host == site || strings.HasSuffix(host, "."+site)
+}
+
+func normalizeSite(site string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(site), "."))
+}
@@ -0,0 +1,27 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		host     string
+		site     string
+		expected bool
+	}{
+		{host: "youtube.com", site: "youtube.com", expected: true},
+		{host: "www.YouTube.com", site: "youtube.com", expected: true},
+		{host: "m.youtube.com", site: " .YouTube.com ", expected: true},
+		{host: "notyoutube.com", site: "youtube.com", expected: false},
+		{host: "youtube.com.evil.net", site: "youtube.com", expected: false},
+		{host: "", site: "youtube.com", expected: false},
+		{host: "youtube.com", site: "", expected: false},
+	}
+	for _, tt := range tests {
+		if got := domain.HostMatches(tt.host, tt.site); got != tt.expected {
+			t.Errorf("HostMatches(%q, %q) = %v, want %v", tt.host, tt.site, got, tt.expected)
+		}
+	}
+}
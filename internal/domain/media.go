@@ -2,8 +2,12 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type Media struct {
@@ -18,14 +22,52 @@ type Media struct {
 	Progress          DownloadProgress  `json:"progress"`
 	IsPlaylist        bool              `json:"is_playlist"`
 	PlaylistSelection PlaylistSelection `json:"playlist_selection,omitempty"`
-	mu                sync.Mutex
+	Format            FormatSelection   `json:"format,omitempty"`
+	Subtitles         SubtitleOptions   `json:"subtitles"`
+	Embed             EmbedOptions      `json:"embed"`
+	Audio             AudioProfile      `json:"audio"`
+	OutputTemplate    string            `json:"output_template,omitempty"` // empty uses the default
+	// AuthProfileID selects the cookies to sign in with; empty uses the
+	// profile registered for the item's site, if any
+	AuthProfileID string `json:"auth_profile_id,omitempty"`
+	// RateLimit overrides the item's share of the global rate limit, in
+	// bytes per second; 0 uses the share
+	RateLimit int64    `json:"rate_limit,omitempty"`
+	Priority  Priority `json:"priority"`
+	// StartAfter holds the item back until the given time
+	StartAfter *time.Time `json:"start_after,omitempty"`
+	// AddedAt is when the item was queued and StartedAt when its first
+	// attempt began
+	AddedAt   time.Time  `json:"added_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// Metadata filled in by the probe step
+	Uploader      string  `json:"uploader"`
+	Duration      float64 `json:"duration"`
+	ThumbnailURL  string  `json:"thumbnail_url"`
+	EstimatedSize int64   `json:"estimated_size"`
+	Extractor     string  `json:"extractor"`
+	// OutputFiles are the final paths of the files yt-dlp wrote, after
+	// merging and converting; a playlist item has one per video
+	OutputFiles []string `json:"output_files,omitempty"`
+	// Entries tracks each video of a playlist item as yt-dlp reaches it
+	Entries      []PlaylistEntry `json:"entries,omitempty"`
+	currentEntry int
+	// Attempts counts the tries since the user last started the item, and
+	// NextRetryAt is set while a retry is scheduled
+	Attempts    int        `json:"attempts"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	ErrorKind   ErrorKind  `json:"error_kind,omitempty"`
+	ErrorHint   string     `json:"error_hint,omitempty"`
+	mu          sync.Mutex
 	// Context for cancellation
 	Ctx        context.Context    `json:"-"`
 	CancelFunc context.CancelFunc `json:"-"`
 
-	OnProgress     func(id string, progress DownloadProgress) `json:"-"`
-	OnStatusChange func(id string, status DownloadStatus)     `json:"-"`
-	OnTitleChange  func(id string, title string)              `json:"-"`
+	OnProgress     func(id string, progress DownloadProgress)  `json:"-"`
+	OnStatusChange func(id string, status DownloadStatus)      `json:"-"`
+	OnTitleChange  func(id string, title string)               `json:"-"`
+	OnEntryChange  func(id string, entry PlaylistEntry)        `json:"-"`
+	OnRetry        func(id string, attempts int, at time.Time) `json:"-"`
 }
 
 type PlaylistSelectionType string
@@ -66,6 +108,44 @@ type DownloadProgress struct {
 	Percentage      int      `json:"percentage"`
 	DownloadedBytes int64    `json:"downloaded_bytes"`
 	Logs            []string `json:"logs"`
+	// Speed is in bytes per second and ETA in seconds; both are 0 when
+	// unknown or when nothing is downloading
+	Speed   float64 `json:"speed"`
+	ETA     int     `json:"eta"`
+	Elapsed float64 `json:"elapsed"` // seconds spent on the current file
+	// Skipped counts the videos skipped because they are already in the
+	// download archive
+	Skipped int `json:"skipped"`
+}
+
+// MarshalJSON encodes the item under its lock, so it can be saved or sent
+// while it downloads
+func (m *Media) MarshalJSON() ([]byte, error) {
+	return m.MarshalJSONWithLogs(-1)
+}
+
+// MarshalJSONWithLogs encodes the item like MarshalJSON but keeps only the
+// last logLines log lines; a negative logLines keeps them all
+func (m *Media) MarshalJSONWithLogs(logLines int) ([]byte, error) {
+	// media has Media's fields without its methods, so encoding it doesn't
+	// call MarshalJSON again
+	type media Media
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	logs := m.Progress.Logs
+	if logLines >= 0 && len(logs) > logLines {
+		m.Progress.Logs = logs[len(logs)-logLines:]
+		defer func() { m.Progress.Logs = logs }()
+	}
+	return json.Marshal((*media)(m))
+}
+
+// CurrentProgress returns the item's progress and total size
+func (m *Media) CurrentProgress() (DownloadProgress, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Progress, m.TotalBytes
 }
 
 func (m *Media) AppendLog(log string) {
@@ -82,6 +162,21 @@ func (m *Media) AppendLog(log string) {
 	}
 }
 
+// RecordSkip counts a video skipped by the download archive and logs it
+func (m *Media) RecordSkip(title string) {
+	m.mu.Lock()
+	m.Progress.Skipped++
+	m.Progress.Logs = append(m.Progress.Logs, "[byto] Skipped (already in download archive): "+title)
+	progress := m.Progress
+	id := m.ID
+	onProgress := m.OnProgress
+	m.mu.Unlock()
+
+	if onProgress != nil {
+		go onProgress(id, progress)
+	}
+}
+
 func (m *Media) SetTitle(title string) {
 	m.mu.Lock()
 	m.Title = title
@@ -94,6 +189,187 @@ func (m *Media) SetTitle(title string) {
 	}
 }
 
+// AddOutputFiles records files yt-dlp finished writing, skipping ones
+// already recorded
+func (m *Media) AddOutputFiles(files ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, file := range files {
+		if file == "" || slices.Contains(m.OutputFiles, file) {
+			continue
+		}
+		m.OutputFiles = append(m.OutputFiles, file)
+	}
+}
+
+// ClearOutputFiles forgets the files of an earlier download, e.g. before
+// downloading the item from scratch
+func (m *Media) ClearOutputFiles() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.OutputFiles = nil
+}
+
+// NeedsFfmpeg reports whether the item's post-processing can't run without
+// ffmpeg (audio or subtitle conversion, or embedding)
+func (m *Media) NeedsFfmpeg() bool {
+	if m.OnlyAudio && m.Audio.Codec != AudioOriginal {
+		return true
+	}
+	// Subtitles are always converted to the chosen format, and embedding
+	// them remuxes the output
+	if m.Subtitles.Enabled && m.Subtitles.Validate() == nil {
+		return true
+	}
+	return m.Embed.Any()
+}
+
+// ResolvedOutputTemplate returns the item's output template. Empty or
+// invalid templates fall back to the playlist-aware default.
+func (m *Media) ResolvedOutputTemplate() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.OutputTemplate != "" && ValidateOutputTemplate(m.OutputTemplate) == nil {
+		return NormalizeOutputTemplate(m.OutputTemplate)
+	}
+	if m.IsPlaylist {
+		return DefaultPlaylistOutputTemplate
+	}
+	return DefaultOutputTemplate
+}
+
+// TemplateFields returns the output template values known before download,
+// used to preview file names
+func (m *Media) TemplateFields() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ext := "mp4"
+	switch {
+	case m.OnlyAudio && m.Audio.Codec != AudioOriginal:
+		ext = string(m.Audio.Codec)
+	case m.OnlyAudio:
+		ext = "webm"
+	case m.Format.Container != ContainerAny:
+		ext = string(m.Format.Container)
+	}
+
+	fields := map[string]string{
+		"title":     m.Title,
+		"fulltitle": m.Title,
+		"uploader":  m.Uploader,
+		"channel":   m.Uploader,
+		"extractor": m.Extractor,
+		"ext":       ext,
+	}
+	if m.Duration > 0 {
+		fields["duration"] = strconv.Itoa(int(m.Duration))
+	}
+	if m.IsPlaylist {
+		fields["playlist"] = m.Title
+		fields["playlist_title"] = m.Title
+		fields["playlist_index"] = "1"
+		fields["playlist_autonumber"] = "1"
+	}
+	return fields
+}
+
+// ApplyInfo copies probed metadata onto the media. Items not added as
+// playlists are probed with --no-playlist, so a probe still finding a
+// playlist means the link has no single video and the item becomes a
+// playlist. The flag is never cleared, so a user's choice stands.
+func (m *Media) ApplyInfo(info MediaInfo) {
+	m.mu.Lock()
+	titleChanged := info.Title != "" && info.Title != m.Title
+	if titleChanged {
+		m.Title = info.Title
+	}
+	m.Uploader = info.Uploader
+	m.Duration = info.Duration
+	m.ThumbnailURL = info.ThumbnailURL
+	m.EstimatedSize = info.EstimatedSize
+	m.Extractor = info.Extractor
+	if info.IsPlaylist {
+		m.IsPlaylist = true
+	}
+	id := m.ID
+	title := m.Title
+	onTitleChange := m.OnTitleChange
+	m.mu.Unlock()
+
+	if titleChanged && onTitleChange != nil {
+		go onTitleChange(id, title)
+	}
+}
+
+// SetTransferStats records the download speed, ETA and elapsed time. They
+// are sent with the next progress update.
+func (m *Media) SetTransferStats(speed float64, eta int, elapsed float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Progress.Speed = speed
+	m.Progress.ETA = eta
+	m.Progress.Elapsed = elapsed
+}
+
+// BeginAttempt counts a new try and clears the previous failure and any
+// scheduled retry. It returns the number of attempts so far.
+func (m *Media) BeginAttempt() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Attempts++
+	if m.StartedAt == nil {
+		now := time.Now()
+		m.StartedAt = &now
+	}
+	m.ErrorKind = ErrorNone
+	m.ErrorHint = ""
+	m.NextRetryAt = nil
+	return m.Attempts
+}
+
+// ResetAttempts gives the item a fresh set of retries, e.g. when the user
+// starts it by hand
+func (m *Media) ResetAttempts() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Attempts = 0
+	m.NextRetryAt = nil
+}
+
+// SetErrorKind records why the last attempt failed and what the user can
+// do about it
+func (m *Media) SetErrorKind(kind ErrorKind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ErrorKind = kind
+	m.ErrorHint = kind.Hint()
+}
+
+// ScheduleRetry records when the item will be tried again
+func (m *Media) ScheduleRetry(at time.Time) {
+	m.mu.Lock()
+	m.NextRetryAt = &at
+	id := m.ID
+	attempts := m.Attempts
+	onRetry := m.OnRetry
+	m.mu.Unlock()
+
+	if onRetry != nil {
+		go onRetry(id, attempts, at)
+	}
+}
+
+// RetryScheduledAt returns the time of the scheduled retry, if any
+func (m *Media) RetryScheduledAt() (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.NextRetryAt == nil {
+		return time.Time{}, false
+	}
+	return *m.NextRetryAt, true
+}
+
 func (m *Media) UpdateProgress(downloaded, total int64, percentage int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -106,10 +382,37 @@ func (m *Media) UpdateProgress(downloaded, total int64, percentage int) {
 	}
 }
 
+// CurrentStatus returns the status, for reading it while the item
+// downloads
+func (m *Media) CurrentStatus() DownloadStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Status
+}
+
+// CurrentURL returns the link the item was added with
+func (m *Media) CurrentURL() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.URL
+}
+
+// CurrentExtractor returns the extractor the probe reported, which is empty
+// until the item was probed
+func (m *Media) CurrentExtractor() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Extractor
+}
+
 func (m *Media) SetStatus(status DownloadStatus) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.Status = status
+	if status != InProgress {
+		m.Progress.Speed = 0
+		m.Progress.ETA = 0
+	}
 	if m.OnStatusChange != nil {
 		go m.OnStatusChange(m.ID, m.Status)
 	}
@@ -5,14 +5,21 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // MediaDefaults stores the user's preferred settings for adding new media items.
 // These are saved and loaded to pre-populate the Add Media dialog.
 type MediaDefaults struct {
-	Quality      VideoQuality `json:"quality"`
-	DownloadPath string       `json:"download_path"`
-	OnlyAudio    bool         `json:"only_audio"`
+	Quality      VideoQuality    `json:"quality"`
+	DownloadPath string          `json:"download_path"`
+	OnlyAudio    bool            `json:"only_audio"`
+	Subtitles    SubtitleOptions `json:"subtitles"`
+	Embed        EmbedOptions    `json:"embed"`
+	Audio        AudioProfile    `json:"audio"`
+	// OutputTemplate is the yt-dlp file name template for new items; empty
+	// uses the built-in (playlist-aware) default
+	OutputTemplate string `json:"output_template"`
 }
 
 func getMediaDefaultsFilePath() string {
@@ -80,8 +87,45 @@ func (m *MediaDefaults) Save() error {
 	return nil
 }
 
+// NewMedia returns a pending media item for the URL that uses the defaults
+func (m *MediaDefaults) NewMedia(id string, url string) *Media {
+	return &Media{
+		ID:             id,
+		URL:            url,
+		Title:          "Pending...",
+		FilePath:       m.DownloadPath,
+		Quality:        m.Quality,
+		OnlyAudio:      m.OnlyAudio,
+		Status:         Pending,
+		AddedAt:        time.Now(),
+		Subtitles:      m.Subtitles,
+		Embed:          m.Embed,
+		Audio:          m.Audio,
+		OutputTemplate: m.OutputTemplate,
+		Progress: DownloadProgress{
+			Logs: []string{},
+		},
+	}
+}
+
 func (m *MediaDefaults) Update(quality VideoQuality, downloadPath string, onlyAudio bool) {
 	m.Quality = quality
 	m.DownloadPath = downloadPath
 	m.OnlyAudio = onlyAudio
 }
+
+func (m *MediaDefaults) UpdateOutputTemplate(template string) {
+	m.OutputTemplate = template
+}
+
+func (m *MediaDefaults) UpdateAudio(audio AudioProfile) {
+	m.Audio = audio
+}
+
+func (m *MediaDefaults) UpdateEmbed(embed EmbedOptions) {
+	m.Embed = embed
+}
+
+func (m *MediaDefaults) UpdateSubtitles(subtitles SubtitleOptions) {
+	m.Subtitles = subtitles
+}
@@ -0,0 +1,63 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestMediaDefaults_NewMedia(t *testing.T) {
+	defaults := &domain.MediaDefaults{
+		Quality:        domain.Quality720p,
+		DownloadPath:   "/downloads",
+		OnlyAudio:      true,
+		Embed:          domain.EmbedOptions{Metadata: true},
+		Audio:          domain.AudioProfile{Codec: domain.AudioMP3},
+		OutputTemplate: "%(title)s.%(ext)s",
+	}
+	m := defaults.NewMedia("id-1", "http://example.com/video")
+
+	if m.ID != "id-1" || m.URL != "http://example.com/video" {
+		t.Errorf("unexpected id/url %q/%q", m.ID, m.URL)
+	}
+	if m.Status != domain.Pending || m.Title != "Pending..." {
+		t.Errorf("expected a pending item, got status %d title %q", m.Status, m.Title)
+	}
+	if m.FilePath != "/downloads" || m.Quality != domain.Quality720p || !m.OnlyAudio {
+		t.Errorf("expected the defaults to be applied, got %+v", m)
+	}
+	if !m.Embed.Metadata || m.Audio.Codec != domain.AudioMP3 || m.OutputTemplate != "%(title)s.%(ext)s" {
+		t.Errorf("expected the format defaults to be applied, got %+v", m)
+	}
+	if m.Progress.Logs == nil {
+		t.Error("expected an empty log list, got nil")
+	}
+}
+
+func TestParseVideoQuality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected domain.VideoQuality
+	}{
+		{"360p", domain.Quality360p},
+		{"480p", domain.Quality480p},
+		{"720p", domain.Quality720p},
+		{"1080p", domain.Quality1080p},
+		{"1440p", domain.Quality1440p},
+		{"2160p", domain.Quality2160p},
+		{"", domain.Quality1080p},
+		{"8k", domain.Quality1080p},
+	}
+	for _, tt := range tests {
+		if got := domain.ParseVideoQuality(tt.input); got != tt.expected {
+			t.Errorf("ParseVideoQuality(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestVideoQuality_StringRoundTrip(t *testing.T) {
+	for _, q := range []domain.VideoQuality{domain.Quality360p, domain.Quality720p, domain.Quality2160p} {
+		if got := domain.ParseVideoQuality(q.String()); got != q {
+			t.Errorf("round trip of %s gave %s", q, got)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+// MediaInfo is the metadata reported by yt-dlp when probing a URL before it
+// is downloaded
+type MediaInfo struct {
+	Title         string  `json:"title"`
+	Uploader      string  `json:"uploader"`
+	Duration      float64 `json:"duration"` // seconds
+	ThumbnailURL  string  `json:"thumbnail_url"`
+	EstimatedSize int64   `json:"estimated_size"` // bytes, 0 if unknown
+	Extractor     string  `json:"extractor"`
+	IsPlaylist    bool    `json:"is_playlist"`
+	PlaylistCount int     `json:"playlist_count"`
+	// Formats lists what the site offers; empty for playlists
+	Formats []FormatInfo `json:"formats"`
+}
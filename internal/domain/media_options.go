@@ -0,0 +1,82 @@
+package domain
+
+import "errors"
+
+// ErrDownloading is returned when an option that yt-dlp was started with is
+// changed while the item downloads
+var ErrDownloading = errors.New("the item is downloading")
+
+// changeIdle applies change under the lock unless the item is downloading,
+// so the check and the change can't race with a worker starting the item
+func (m *Media) changeIdle(change func()) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Status.IsRunning() {
+		return ErrDownloading
+	}
+	change()
+	return nil
+}
+
+// SetFormat changes the format picked for the item
+func (m *Media) SetFormat(selection FormatSelection) error {
+	return m.changeIdle(func() { m.Format = selection })
+}
+
+// SetSubtitles changes the subtitle options
+func (m *Media) SetSubtitles(subtitles SubtitleOptions) error {
+	return m.changeIdle(func() { m.Subtitles = subtitles })
+}
+
+// SetOutputTemplate changes the file name template; empty uses the default
+func (m *Media) SetOutputTemplate(template string) error {
+	return m.changeIdle(func() { m.OutputTemplate = template })
+}
+
+// SetAudio changes the audio codec and bitrate
+func (m *Media) SetAudio(audio AudioProfile) error {
+	return m.changeIdle(func() { m.Audio = audio })
+}
+
+// SetEmbed changes what gets embedded into the output file
+func (m *Media) SetEmbed(embed EmbedOptions) error {
+	return m.changeIdle(func() { m.Embed = embed })
+}
+
+// SetAuthProfileID changes the authentication profile the item signs in
+// with; empty falls back to the profile for its site
+func (m *Media) SetAuthProfileID(profileID string) error {
+	return m.changeIdle(func() { m.AuthProfileID = profileID })
+}
+
+// ForgetAuthProfile drops the item's authentication profile if it is the
+// given one, e.g. after the profile was deleted. A running download keeps
+// the profile it started with.
+func (m *Media) ForgetAuthProfile(profileID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.AuthProfileID == profileID {
+		m.AuthProfileID = ""
+	}
+}
+
+// SetRateLimit changes the item's own rate limit; 0 uses a share of the
+// global one
+func (m *Media) SetRateLimit(bytesPerSecond int64) error {
+	return m.changeIdle(func() { m.RateLimit = bytesPerSecond })
+}
+
+// SetPriority changes the item's priority. It may change while the item
+// downloads, as it only matters for starting it.
+func (m *Media) SetPriority(priority Priority) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Priority = priority
+}
+
+// CurrentPriority returns the item's priority
+func (m *Media) CurrentPriority() Priority {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Priority
+}
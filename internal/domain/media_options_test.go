@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"errors"
+	"testing"
+)
+
+func TestMedia_SettersRefuseWhileDownloading(t *testing.T) {
+	for _, status := range []domain.DownloadStatus{domain.InProgress, domain.Processing} {
+		m := &domain.Media{Status: status}
+		if err := m.SetFormat(domain.FormatSelection{FormatID: "22"}); !errors.Is(err, domain.ErrDownloading) {
+			t.Errorf("%s: expected ErrDownloading, got %v", status, err)
+		}
+		if err := m.SetRateLimit(1024); !errors.Is(err, domain.ErrDownloading) {
+			t.Errorf("%s: expected ErrDownloading, got %v", status, err)
+		}
+		if m.Format.FormatID != "" || m.RateLimit != 0 {
+			t.Errorf("%s: expected the options unchanged, got %+v and %d", status, m.Format, m.RateLimit)
+		}
+	}
+
+	m := &domain.Media{Status: domain.Paused}
+	if err := m.SetOutputTemplate("%(title)s.%(ext)s"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.OutputTemplate != "%(title)s.%(ext)s" {
+		t.Errorf("expected the template to be set, got %q", m.OutputTemplate)
+	}
+}
+
+func TestMedia_ForgetAuthProfile(t *testing.T) {
+	m := &domain.Media{AuthProfileID: "a"}
+	m.ForgetAuthProfile("b")
+	if m.AuthProfileID != "a" {
+		t.Errorf("expected another profile to be kept, got %q", m.AuthProfileID)
+	}
+	m.ForgetAuthProfile("a")
+	if m.AuthProfileID != "" {
+		t.Errorf("expected the profile to be dropped, got %q", m.AuthProfileID)
+	}
+}
+
+func TestMedia_SettersWhileEncoding(t *testing.T) {
+	m := &domain.Media{ID: "1", Status: domain.Pending}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			m.SetSubtitles(domain.SubtitleOptions{Enabled: i%2 == 0})
+			m.SetPriority(domain.PriorityHigh)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		if _, err := m.MarshalJSON(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
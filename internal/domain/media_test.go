@@ -132,6 +132,87 @@ func TestSetTitle_NoCallbackNoPanic(t *testing.T) {
 	m.SetTitle("safe")
 }
 
+// ===========================================================================
+// ApplyInfo
+// ===========================================================================
+
+func TestApplyInfo_SetsMetadata(t *testing.T) {
+	m := &domain.Media{ID: "1", Title: "Pending..."}
+	m.ApplyInfo(domain.MediaInfo{
+		Title:         "Real Title",
+		Uploader:      "Uploader",
+		Duration:      90.5,
+		ThumbnailURL:  "https://example.com/t.jpg",
+		EstimatedSize: 4096,
+		Extractor:     "youtube",
+	})
+	if m.Title != "Real Title" {
+		t.Errorf("expected 'Real Title', got %q", m.Title)
+	}
+	if m.Uploader != "Uploader" || m.Duration != 90.5 || m.ThumbnailURL != "https://example.com/t.jpg" {
+		t.Errorf("metadata not applied: %+v", m)
+	}
+	if m.EstimatedSize != 4096 || m.Extractor != "youtube" {
+		t.Errorf("size/extractor not applied: size=%d extractor=%q", m.EstimatedSize, m.Extractor)
+	}
+}
+
+func TestApplyInfo_EmptyTitleKeepsExisting(t *testing.T) {
+	m := &domain.Media{ID: "1", Title: "Keep Me"}
+	m.ApplyInfo(domain.MediaInfo{})
+	if m.Title != "Keep Me" {
+		t.Errorf("expected 'Keep Me', got %q", m.Title)
+	}
+}
+
+func TestApplyInfo_SetsPlaylistFlag(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.ApplyInfo(domain.MediaInfo{IsPlaylist: true})
+	if !m.IsPlaylist {
+		t.Error("expected IsPlaylist=true after probe reports a playlist")
+	}
+}
+
+func TestApplyInfo_KeepsChosenPlaylistFlag(t *testing.T) {
+	m := &domain.Media{ID: "1", IsPlaylist: true}
+	m.ApplyInfo(domain.MediaInfo{IsPlaylist: false})
+	if !m.IsPlaylist {
+		t.Error("expected the playlist the user asked for to be kept")
+	}
+}
+
+func TestApplyInfo_CallsOnTitleChange(t *testing.T) {
+	var called int32
+	m := &domain.Media{
+		ID:    "1",
+		Title: "Pending...",
+		OnTitleChange: func(id string, title string) {
+			atomic.AddInt32(&called, 1)
+		},
+	}
+	m.ApplyInfo(domain.MediaInfo{Title: "New"})
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&called) != 1 {
+		t.Errorf("expected OnTitleChange to be called once, got %d", called)
+	}
+}
+
+func TestApplyInfo_SameTitleNoCallback(t *testing.T) {
+	var called int32
+	m := &domain.Media{
+		ID:    "1",
+		Title: "Same",
+		OnTitleChange: func(id string, title string) {
+			atomic.AddInt32(&called, 1)
+		},
+	}
+	m.ApplyInfo(domain.MediaInfo{Title: "Same"})
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&called) != 0 {
+		t.Error("OnTitleChange should not be called when the title is unchanged")
+	}
+}
+
 func TestUpdateProgress_SetsValues(t *testing.T) {
 	m := &domain.Media{ID: "1"}
 	m.UpdateProgress(500, 1000, 50)
@@ -626,3 +707,63 @@ func containsSubstring(s, sub string) bool {
 			return false
 		})())
 }
+
+// ---------------------------------------------------------------------------
+// Retries
+// ---------------------------------------------------------------------------
+
+func TestBeginAttempt_CountsAndClearsFailure(t *testing.T) {
+	m := &domain.Media{ID: "1", ErrorKind: domain.ErrorNetwork}
+	m.ScheduleRetry(time.Now())
+	if n := m.BeginAttempt(); n != 1 {
+		t.Errorf("expected attempt 1, got %d", n)
+	}
+	if m.ErrorKind != domain.ErrorNone {
+		t.Errorf("expected the error kind to be cleared, got %q", m.ErrorKind)
+	}
+	if _, ok := m.RetryScheduledAt(); ok {
+		t.Error("expected the scheduled retry to be cleared")
+	}
+	if n := m.BeginAttempt(); n != 2 {
+		t.Errorf("expected attempt 2, got %d", n)
+	}
+}
+
+func TestResetAttempts(t *testing.T) {
+	m := &domain.Media{ID: "1", Attempts: 3}
+	m.ScheduleRetry(time.Now())
+	m.ResetAttempts()
+	if m.Attempts != 0 {
+		t.Errorf("expected 0 attempts, got %d", m.Attempts)
+	}
+	if _, ok := m.RetryScheduledAt(); ok {
+		t.Error("expected the scheduled retry to be cleared")
+	}
+}
+
+func TestScheduleRetry_CallsOnRetry(t *testing.T) {
+	at := time.Now().Add(time.Minute)
+	done := make(chan int, 1)
+	m := &domain.Media{
+		ID:       "1",
+		Attempts: 2,
+		OnRetry: func(id string, attempts int, retryAt time.Time) {
+			if retryAt.Equal(at) {
+				done <- attempts
+			}
+		},
+	}
+	m.ScheduleRetry(at)
+
+	select {
+	case attempts := <-done:
+		if attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", attempts)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnRetry callback was not called")
+	}
+	if scheduled, ok := m.RetryScheduledAt(); !ok || !scheduled.Equal(at) {
+		t.Errorf("expected retry at %v, got %v", at, scheduled)
+	}
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// IPVersion forces connections over one IP version
+type IPVersion string
+
+const (
+	IPAny IPVersion = ""
+	IPv4  IPVersion = "ipv4"
+	IPv6  IPVersion = "ipv6"
+)
+
+// proxySchemes are the proxy types both yt-dlp and the updater support
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// NetworkSettings routes yt-dlp and the updater through a proxy and
+// controls how they connect. The zero value connects directly with the
+// tools' defaults.
+type NetworkSettings struct {
+	// ProxyURL is e.g. "http://proxy.corp:3128" or "socks5://127.0.0.1:1080";
+	// ProxyUsername and ProxyPassword are added to it when set
+	ProxyURL      string `json:"proxy_url"`
+	ProxyUsername string `json:"proxy_username,omitempty"`
+	ProxyPassword string `json:"proxy_password,omitempty"`
+	// SourceAddress is the local IP address to connect from
+	SourceAddress string    `json:"source_address"`
+	IPVersion     IPVersion `json:"ip_version"`
+	// SocketTimeoutSeconds is how long to wait for a connection or data;
+	// 0 keeps the default
+	SocketTimeoutSeconds int `json:"socket_timeout_seconds"`
+}
+
+func (n NetworkSettings) Validate() error {
+	if n.ProxyURL != "" {
+		u, err := url.Parse(n.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q", n.ProxyURL)
+		}
+		if !isProxyScheme(u.Scheme) {
+			return fmt.Errorf("unsupported proxy type %q, expected one of http, https, socks5, socks5h", u.Scheme)
+		}
+	} else if n.ProxyUsername != "" {
+		return errors.New("proxy username set without a proxy URL")
+	}
+	if n.SourceAddress != "" && net.ParseIP(n.SourceAddress) == nil {
+		return fmt.Errorf("invalid source address %q", n.SourceAddress)
+	}
+	switch n.IPVersion {
+	case IPAny, IPv4, IPv6:
+	default:
+		return fmt.Errorf("invalid IP version %q", n.IPVersion)
+	}
+	if n.SocketTimeoutSeconds < 0 {
+		return fmt.Errorf("socket timeout must not be negative, got %d", n.SocketTimeoutSeconds)
+	}
+	return nil
+}
+
+func isProxyScheme(scheme string) bool {
+	for _, s := range proxySchemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// Proxy returns the proxy URL with the credentials filled in, or nil when no
+// proxy is set
+func (n NetworkSettings) Proxy() (*url.URL, error) {
+	if n.ProxyURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(n.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", n.ProxyURL, err)
+	}
+	if n.ProxyUsername != "" {
+		u.User = url.UserPassword(n.ProxyUsername, n.ProxyPassword)
+	}
+	return u, nil
+}
+
+// RedactedProxy returns the proxy URL with its password masked, for logging
+func (n NetworkSettings) RedactedProxy() string {
+	proxy, err := n.Proxy()
+	if err != nil || proxy == nil {
+		return ""
+	}
+	return proxy.Redacted()
+}
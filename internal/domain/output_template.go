@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultOutputTemplate         = "%(title).100s.%(ext)s"
+	DefaultPlaylistOutputTemplate = "%(playlist_title)s/%(playlist_index)03d - %(title).100s.%(ext)s"
+)
+
+// templateFieldRegex matches one yt-dlp output template field, e.g.
+// %(title).100s or %(playlist_index)03d
+var templateFieldRegex = regexp.MustCompile(`%\(([^)]*)\)([-#0+ ]*\d*(?:\.\d+)?)([diouxXeEfFgGcrsaBjlqDSU])`)
+
+var templateFieldNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// allowedTemplateFields lists the output template fields users may use
+var allowedTemplateFields = map[string]bool{
+	"id": true, "title": true, "fulltitle": true, "ext": true, "alt_title": true,
+	"uploader": true, "uploader_id": true, "channel": true, "channel_id": true, "creator": true,
+	"upload_date": true, "release_date": true, "timestamp": true, "epoch": true,
+	"duration": true, "duration_string": true, "view_count": true, "like_count": true,
+	"playlist": true, "playlist_title": true, "playlist_id": true, "playlist_index": true,
+	"playlist_count": true, "playlist_autonumber": true, "playlist_uploader": true, "autonumber": true,
+	"extractor": true, "extractor_key": true, "webpage_url_domain": true,
+	"format_id": true, "resolution": true, "width": true, "height": true, "fps": true,
+	"vcodec": true, "acodec": true, "language": true,
+	"series": true, "season": true, "season_number": true, "episode": true, "episode_number": true,
+	"track": true, "track_number": true, "artist": true, "album": true, "album_artist": true,
+}
+
+// ValidateOutputTemplate checks that a template only uses known fields and
+// stays inside the download folder. An empty template is valid and means
+// the default.
+func ValidateOutputTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if strings.HasPrefix(template, "/") || strings.HasPrefix(template, "\\") || (len(template) > 1 && template[1] == ':') {
+		return fmt.Errorf("output template must be relative to the download folder")
+	}
+	for _, segment := range strings.FieldsFunc(template, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("output template must not leave the download folder")
+		}
+	}
+
+	for _, match := range templateFieldRegex.FindAllStringSubmatch(template, -1) {
+		for _, name := range templateFieldNames(match[1]) {
+			if !allowedTemplateFields[name] {
+				return fmt.Errorf("unknown output template field: %q", name)
+			}
+		}
+	}
+
+	// Anything left that still looks like a field is malformed
+	rest := templateFieldRegex.ReplaceAllString(strings.ReplaceAll(template, "%%", ""), "")
+	if strings.Contains(rest, "%(") {
+		return fmt.Errorf("malformed output template field in %q", template)
+	}
+	return nil
+}
+
+// NormalizeOutputTemplate appends the extension field when the template
+// doesn't have one, so files always keep their extension
+func NormalizeOutputTemplate(template string) string {
+	if strings.Contains(template, "%(ext)") {
+		return template
+	}
+	return template + ".%(ext)s"
+}
+
+// RenderOutputTemplate fills a template with the given field values to
+// preview the resulting file name. Missing fields render as "NA" like
+// yt-dlp does.
+func RenderOutputTemplate(template string, fields map[string]string) (string, error) {
+	if err := ValidateOutputTemplate(template); err != nil {
+		return "", err
+	}
+
+	rendered := templateFieldRegex.ReplaceAllStringFunc(template, func(field string) string {
+		match := templateFieldRegex.FindStringSubmatch(field)
+		expr, spec, conversion := match[1], match[2], match[3]
+
+		value, ok := "", false
+		def := "NA"
+		if i := strings.Index(expr, "|"); i >= 0 {
+			def = expr[i+1:]
+			expr = expr[:i]
+		}
+		for _, name := range templateFieldNames(expr) {
+			if value, ok = fields[name]; ok && value != "" {
+				break
+			}
+		}
+		if !ok || value == "" {
+			return def
+		}
+
+		switch conversion {
+		case "d", "i":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return value
+			}
+			return fmt.Sprintf("%"+spec+"d", n)
+		default:
+			return fmt.Sprintf("%"+spec+"s", value)
+		}
+	})
+	return strings.ReplaceAll(rendered, "%%", "%"), nil
+}
+
+// templateFieldNames returns the base field names referenced by a field
+// expression such as "uploader,channel|Unknown" or "playlist_index+1"
+func templateFieldNames(expr string) []string {
+	if i := strings.Index(expr, "|"); i >= 0 {
+		expr = expr[:i]
+	}
+	var names []string
+	for _, alt := range strings.Split(expr, ",") {
+		if name := templateFieldNameRegex.FindString(strings.TrimSpace(alt)); name != "" {
+			names = append(names, name)
+		} else {
+			names = append(names, alt)
+		}
+	}
+	return names
+}
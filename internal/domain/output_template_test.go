@@ -0,0 +1,141 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestValidateOutputTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		expectError bool
+	}{
+		{name: "empty", template: ""},
+		{name: "default", template: domain.DefaultOutputTemplate},
+		{name: "playlist default", template: domain.DefaultPlaylistOutputTemplate},
+		{name: "uploader and date folders", template: "%(uploader)s/%(upload_date)s - %(title)s.%(ext)s"},
+		{name: "alternatives and default", template: "%(uploader,channel|Unknown)s/%(title)s"},
+		{name: "arithmetic on index", template: "%(playlist_index+1)03d"},
+		{name: "literal percent", template: "100%% %(title)s"},
+		{name: "plain text", template: "video"},
+		{name: "unknown field", template: "%(password)s", expectError: true},
+		{name: "unknown alternative", template: "%(title,secret)s", expectError: true},
+		{name: "unterminated field", template: "%(title", expectError: true},
+		{name: "missing conversion", template: "%(title)", expectError: true},
+		{name: "absolute unix path", template: "/etc/%(title)s", expectError: true},
+		{name: "absolute windows path", template: "C:\\%(title)s", expectError: true},
+		{name: "parent directory", template: "../%(title)s", expectError: true},
+		{name: "nested parent directory", template: "%(uploader)s/../../%(title)s", expectError: true},
+		{name: "dots in name are fine", template: "%(title)s...%(ext)s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.ValidateOutputTemplate(tt.template)
+			if tt.expectError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeOutputTemplate(t *testing.T) {
+	if got := domain.NormalizeOutputTemplate("%(title)s"); got != "%(title)s.%(ext)s" {
+		t.Errorf("expected ext to be appended, got %q", got)
+	}
+	if got := domain.NormalizeOutputTemplate(domain.DefaultOutputTemplate); got != domain.DefaultOutputTemplate {
+		t.Errorf("expected template with ext to be unchanged, got %q", got)
+	}
+}
+
+func TestRenderOutputTemplate(t *testing.T) {
+	fields := map[string]string{
+		"title":          "My Video",
+		"uploader":       "Someone",
+		"ext":            "mp4",
+		"playlist_title": "My List",
+		"playlist_index": "7",
+	}
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		expectError bool
+	}{
+		{name: "default", template: domain.DefaultOutputTemplate, expected: "My Video.mp4"},
+		{name: "playlist default", template: domain.DefaultPlaylistOutputTemplate, expected: "My List/007 - My Video.mp4"},
+		{name: "truncation", template: "%(title).2s", expected: "My"},
+		{name: "missing field", template: "%(upload_date)s", expected: "NA"},
+		{name: "default value", template: "%(channel|Unknown)s", expected: "Unknown"},
+		{name: "alternative", template: "%(channel,uploader)s", expected: "Someone"},
+		{name: "literal percent", template: "100%% %(ext)s", expected: "100% mp4"},
+		{name: "invalid", template: "%(secret)s", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.RenderOutputTemplate(tt.template, fields)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMedia_ResolvedOutputTemplate(t *testing.T) {
+	if got := (&domain.Media{}).ResolvedOutputTemplate(); got != domain.DefaultOutputTemplate {
+		t.Errorf("expected default template, got %q", got)
+	}
+	if got := (&domain.Media{IsPlaylist: true}).ResolvedOutputTemplate(); got != domain.DefaultPlaylistOutputTemplate {
+		t.Errorf("expected playlist template, got %q", got)
+	}
+	if got := (&domain.Media{IsPlaylist: true, OutputTemplate: "%(uploader)s/%(title)s"}).ResolvedOutputTemplate(); got != "%(uploader)s/%(title)s.%(ext)s" {
+		t.Errorf("expected custom template with ext, got %q", got)
+	}
+	if got := (&domain.Media{IsPlaylist: true, OutputTemplate: "../%(title)s"}).ResolvedOutputTemplate(); got != domain.DefaultPlaylistOutputTemplate {
+		t.Errorf("expected an invalid playlist template to fall back to the playlist default, got %q", got)
+	}
+	if got := (&domain.Media{OutputTemplate: "%(bogus)s"}).ResolvedOutputTemplate(); got != domain.DefaultOutputTemplate {
+		t.Errorf("expected an invalid template to fall back to the default, got %q", got)
+	}
+}
+
+func TestMedia_TemplateFields(t *testing.T) {
+	m := &domain.Media{Title: "T", Uploader: "U", Duration: 61.7, OnlyAudio: true, Audio: domain.AudioProfile{Codec: domain.AudioMP3}}
+	fields := m.TemplateFields()
+	if fields["title"] != "T" || fields["uploader"] != "U" || fields["duration"] != "61" || fields["ext"] != "mp3" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if _, ok := fields["playlist_index"]; ok {
+		t.Error("single items should not have playlist fields")
+	}
+
+	playlist := &domain.Media{Title: "List", IsPlaylist: true, Format: domain.FormatSelection{Container: domain.ContainerMKV}}
+	name, err := domain.RenderOutputTemplate(playlist.ResolvedOutputTemplate(), playlist.TemplateFields())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(name, "List/001 - ") || !strings.HasSuffix(name, ".mkv") {
+		t.Errorf("unexpected playlist preview: %q", name)
+	}
+}
+
+func TestMediaDefaults_UpdateOutputTemplate(t *testing.T) {
+	m := &domain.MediaDefaults{}
+	m.UpdateOutputTemplate("%(uploader)s/%(title)s")
+	if m.OutputTemplate != "%(uploader)s/%(title)s" {
+		t.Errorf("unexpected output template: %q", m.OutputTemplate)
+	}
+}
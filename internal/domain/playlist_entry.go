@@ -0,0 +1,171 @@
+package domain
+
+// PlaylistEntry tracks one video of a playlist item. Index is the 1-based
+// position among the videos yt-dlp downloads, which differs from the
+// playlist index when only some items are selected.
+type PlaylistEntry struct {
+	Index           int            `json:"index"`
+	Title           string         `json:"title"`
+	Status          DownloadStatus `json:"status"`
+	Percentage      int            `json:"percentage"`
+	DownloadedBytes int64          `json:"downloaded_bytes"`
+	TotalBytes      int64          `json:"total_bytes"`
+}
+
+// IsDone reports whether yt-dlp has moved past the entry
+func (e PlaylistEntry) IsDone() bool {
+	return e.Status == Completed || e.Status == Failed || e.Status == Skipped
+}
+
+// StartEntry marks the entry at index as downloading. The first call creates
+// count pending entries. Any entry still running is finished as completed,
+// since yt-dlp only moves on once the previous video is done.
+func (m *Media) StartEntry(index, count int) {
+	if index < 1 {
+		return
+	}
+	if count < index {
+		count = index
+	}
+
+	m.mu.Lock()
+	for len(m.Entries) < count {
+		m.Entries = append(m.Entries, PlaylistEntry{Index: len(m.Entries) + 1, Status: Pending})
+	}
+
+	var changed []PlaylistEntry
+	if m.currentEntry > 0 && m.currentEntry != index && m.Entries[m.currentEntry-1].Status.IsRunning() {
+		m.Entries[m.currentEntry-1].Status = Completed
+		m.Entries[m.currentEntry-1].Percentage = 100
+		changed = append(changed, m.Entries[m.currentEntry-1])
+	}
+	m.currentEntry = index
+	m.Entries[index-1].Status = InProgress
+	changed = append(changed, m.Entries[index-1])
+	m.aggregateEntries()
+	m.mu.Unlock()
+
+	m.emitEntries(changed)
+}
+
+// CurrentEntry returns the index of the entry being downloaded, or 0 when
+// the item isn't tracked per entry
+func (m *Media) CurrentEntry() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.currentEntry
+}
+
+// UpdateEntryProgress records the progress of the current entry and
+// recomputes the item's overall progress from all entries
+func (m *Media) UpdateEntryProgress(title string, downloaded, total int64, percentage int) {
+	m.mu.Lock()
+	if m.currentEntry == 0 {
+		m.mu.Unlock()
+		return
+	}
+	entry := &m.Entries[m.currentEntry-1]
+	if title != "" {
+		entry.Title = title
+	}
+	entry.Status = InProgress
+	entry.DownloadedBytes = downloaded
+	entry.TotalBytes = total
+	entry.Percentage = percentage
+	changed := []PlaylistEntry{*entry}
+	m.aggregateEntries()
+	m.mu.Unlock()
+
+	m.emitEntries(changed)
+}
+
+// SetEntryStatus changes the status of the current entry. A title, when
+// given, replaces the entry's title.
+func (m *Media) SetEntryStatus(status DownloadStatus, title string) {
+	m.mu.Lock()
+	if m.currentEntry == 0 {
+		m.mu.Unlock()
+		return
+	}
+	entry := &m.Entries[m.currentEntry-1]
+	if entry.Status == status && (title == "" || title == entry.Title) {
+		m.mu.Unlock()
+		return
+	}
+	entry.Status = status
+	if title != "" {
+		entry.Title = title
+	}
+	if status == Completed || status == Skipped {
+		entry.Percentage = 100
+	}
+	changed := []PlaylistEntry{*entry}
+	m.aggregateEntries()
+	m.mu.Unlock()
+
+	m.emitEntries(changed)
+}
+
+// FinishEntries gives every running entry the final status once yt-dlp has
+// exited and stops tracking the current entry
+func (m *Media) FinishEntries(status DownloadStatus) {
+	m.mu.Lock()
+	var changed []PlaylistEntry
+	for i := range m.Entries {
+		if m.Entries[i].Status.IsRunning() {
+			m.Entries[i].Status = status
+			if status == Completed {
+				m.Entries[i].Percentage = 100
+			}
+			changed = append(changed, m.Entries[i])
+		}
+	}
+	m.currentEntry = 0
+	if len(changed) > 0 {
+		m.aggregateEntries()
+	}
+	m.mu.Unlock()
+
+	m.emitEntries(changed)
+}
+
+// aggregateEntries sets the item's progress from its entries. Entries yt-dlp
+// is done with count as fully processed. Callers must hold m.mu.
+func (m *Media) aggregateEntries() {
+	if len(m.Entries) == 0 {
+		return
+	}
+
+	var percentage int
+	var downloaded, total int64
+	for _, e := range m.Entries {
+		if e.IsDone() {
+			percentage += 100
+		} else {
+			percentage += e.Percentage
+		}
+		downloaded += e.DownloadedBytes
+		total += e.TotalBytes
+	}
+	m.Progress.Percentage = percentage / len(m.Entries)
+	m.Progress.DownloadedBytes = downloaded
+	m.TotalBytes = total
+
+	if m.OnProgress != nil {
+		go m.OnProgress(m.ID, m.Progress)
+	}
+}
+
+func (m *Media) emitEntries(entries []PlaylistEntry) {
+	m.mu.Lock()
+	id := m.ID
+	onEntryChange := m.OnEntryChange
+	m.mu.Unlock()
+
+	if onEntryChange == nil {
+		return
+	}
+	for _, e := range entries {
+		go onEntryChange(id, e)
+	}
+}
@@ -0,0 +1,145 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartEntry_CreatesPendingEntries(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 3)
+
+	if len(m.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(m.Entries))
+	}
+	if m.Entries[0].Status != domain.InProgress {
+		t.Errorf("expected entry 1 InProgress, got %d", m.Entries[0].Status)
+	}
+	for i, e := range m.Entries[1:] {
+		if e.Status != domain.Pending || e.Index != i+2 {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	if m.CurrentEntry() != 1 {
+		t.Errorf("expected current entry 1, got %d", m.CurrentEntry())
+	}
+}
+
+func TestStartEntry_CompletesPreviousEntry(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 2)
+	m.UpdateEntryProgress("First", 50, 100, 50)
+	m.StartEntry(2, 2)
+
+	if m.Entries[0].Status != domain.Completed || m.Entries[0].Percentage != 100 {
+		t.Errorf("expected entry 1 completed at 100%%, got %+v", m.Entries[0])
+	}
+	if m.Entries[1].Status != domain.InProgress {
+		t.Errorf("expected entry 2 InProgress, got %d", m.Entries[1].Status)
+	}
+}
+
+func TestStartEntry_KeepsFailedEntry(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 2)
+	m.SetEntryStatus(domain.Failed, "")
+	m.StartEntry(2, 2)
+
+	if m.Entries[0].Status != domain.Failed {
+		t.Errorf("expected entry 1 to stay Failed, got %d", m.Entries[0].Status)
+	}
+}
+
+func TestStartEntry_InvalidIndexIgnored(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(0, 5)
+	if len(m.Entries) != 0 || m.CurrentEntry() != 0 {
+		t.Errorf("expected no entries, got %v", m.Entries)
+	}
+}
+
+func TestUpdateEntryProgress_AggregatesParent(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 4)
+	m.UpdateEntryProgress("First", 100, 100, 100)
+	m.StartEntry(2, 4)
+	m.UpdateEntryProgress("Second", 100, 200, 50)
+
+	// (100 + 50 + 0 + 0) / 4
+	if m.Progress.Percentage != 37 {
+		t.Errorf("expected aggregate 37%%, got %d", m.Progress.Percentage)
+	}
+	if m.Progress.DownloadedBytes != 200 || m.TotalBytes != 300 {
+		t.Errorf("expected 200/300 bytes, got %d/%d", m.Progress.DownloadedBytes, m.TotalBytes)
+	}
+	if m.Entries[1].Title != "Second" {
+		t.Errorf("expected entry title 'Second', got %q", m.Entries[1].Title)
+	}
+}
+
+func TestUpdateEntryProgress_NoCurrentEntryIgnored(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.UpdateEntryProgress("Video", 50, 100, 50)
+	if m.Progress.Percentage != 0 || len(m.Entries) != 0 {
+		t.Error("expected no change without a current entry")
+	}
+}
+
+func TestSetEntryStatus_SkippedCountsAsDone(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 2)
+	m.SetEntryStatus(domain.Skipped, "Archived")
+
+	if m.Entries[0].Status != domain.Skipped || m.Entries[0].Title != "Archived" {
+		t.Errorf("unexpected entry %+v", m.Entries[0])
+	}
+	if m.Progress.Percentage != 50 {
+		t.Errorf("expected aggregate 50%%, got %d", m.Progress.Percentage)
+	}
+}
+
+func TestFinishEntries_FinalizesRunningEntries(t *testing.T) {
+	m := &domain.Media{ID: "1"}
+	m.StartEntry(1, 2)
+	m.SetEntryStatus(domain.Failed, "")
+	m.StartEntry(2, 2)
+	m.FinishEntries(domain.Completed)
+
+	if m.Entries[0].Status != domain.Failed {
+		t.Errorf("expected entry 1 to stay Failed, got %d", m.Entries[0].Status)
+	}
+	if m.Entries[1].Status != domain.Completed {
+		t.Errorf("expected entry 2 Completed, got %d", m.Entries[1].Status)
+	}
+	if m.CurrentEntry() != 0 {
+		t.Errorf("expected no current entry, got %d", m.CurrentEntry())
+	}
+	if m.Progress.Percentage != 100 {
+		t.Errorf("expected aggregate 100%%, got %d", m.Progress.Percentage)
+	}
+}
+
+func TestEntryChanges_CallOnEntryChange(t *testing.T) {
+	var mu sync.Mutex
+	var received []domain.PlaylistEntry
+	m := &domain.Media{
+		ID: "1",
+		OnEntryChange: func(id string, entry domain.PlaylistEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, entry)
+		},
+	}
+	m.StartEntry(1, 2)
+	m.StartEntry(2, 2)
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// entry 1 started, entry 1 completed, entry 2 started
+	if len(received) != 3 {
+		t.Errorf("expected 3 entry updates, got %v", received)
+	}
+}
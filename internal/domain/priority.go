@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority orders downloads: higher priorities start first, and items of
+// equal priority start in queue order
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+func (p Priority) Validate() error {
+	if p < PriorityLow || p > PriorityHigh {
+		return fmt.Errorf("invalid priority %d, expected -1 (low), 0 (normal) or 1 (high)", p)
+	}
+	return nil
+}
+
+// ParsePriority reads "low", "normal" or "high"
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	default:
+		return PriorityNormal, fmt.Errorf("invalid priority %q, expected low, normal or high", s)
+	}
+}
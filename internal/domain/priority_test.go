@@ -0,0 +1,41 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected domain.Priority
+	}{
+		{"low", domain.PriorityLow},
+		{"", domain.PriorityNormal},
+		{"Normal", domain.PriorityNormal},
+		{" HIGH ", domain.PriorityHigh},
+	}
+	for _, tt := range tests {
+		got, err := domain.ParsePriority(tt.input)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParsePriority(%q) = %v, %v, expected %v", tt.input, got, err, tt.expected)
+		}
+	}
+	if _, err := domain.ParsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+}
+
+func TestPriority_StringAndValidate(t *testing.T) {
+	for p, name := range map[domain.Priority]string{domain.PriorityLow: "low", domain.PriorityNormal: "normal", domain.PriorityHigh: "high"} {
+		if p.String() != name {
+			t.Errorf("Priority(%d).String() = %q, expected %q", p, p.String(), name)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("Priority(%d).Validate() unexpected error: %v", p, err)
+		}
+	}
+	if err := domain.Priority(5).Validate(); err == nil {
+		t.Error("expected an error for an out of range priority")
+	}
+}
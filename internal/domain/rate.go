@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseRate reads a download rate in bytes per second as written for
+// yt-dlp's --limit-rate, e.g. "500K", "1.5M" or "2000000". Suffixes are
+// binary (K = 1024) and case-insensitive; a trailing "B" or "/s" is ignored.
+// An empty string or "0" means unlimited.
+func ParseRate(s string) (int64, error) {
+	rate := strings.ToUpper(strings.TrimSpace(s))
+	rate = strings.TrimSuffix(rate, "/S")
+	rate = strings.TrimSuffix(rate, "IB")
+	rate = strings.TrimSuffix(rate, "B")
+	if rate == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	switch rate[len(rate)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		rate = rate[:len(rate)-1]
+	}
+
+	value, err := strconv.ParseFloat(rate, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected e.g. 500K or 2M", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatRate returns a rate in bytes per second as e.g. "1.5 MiB/s", or
+// "unlimited" for 0
+func FormatRate(bytesPerSecond int64) string {
+	if bytesPerSecond <= 0 {
+		return "unlimited"
+	}
+	const unit = 1024
+	if bytesPerSecond < unit {
+		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	}
+	div, exp := int64(unit), 0
+	for v := bytesPerSecond / unit; v >= unit && exp < 2; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB/s", float64(bytesPerSecond)/float64(div), "KMG"[exp])
+}
@@ -0,0 +1,59 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"2000000", 2000000},
+		{"500K", 500 * 1024},
+		{"500k", 500 * 1024},
+		{"1.5M", 1536 * 1024},
+		{"2MiB/s", 2 * 1024 * 1024},
+		{"1G", 1 << 30},
+		{" 64KB ", 64 * 1024},
+	}
+	for _, tt := range tests {
+		got, err := domain.ParseRate(tt.input)
+		if err != nil {
+			t.Errorf("ParseRate(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseRate(%q) = %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseRate_Invalid(t *testing.T) {
+	for _, input := range []string{"fast", "-5M", "M", "1.2.3K"} {
+		if _, err := domain.ParseRate(input); err == nil {
+			t.Errorf("ParseRate(%q) expected an error", input)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "unlimited"},
+		{512, "512 B/s"},
+		{1536, "1.5 KiB/s"},
+		{5 * 1024 * 1024, "5.0 MiB/s"},
+		{3 << 30, "3.0 GiB/s"},
+	}
+	for _, tt := range tests {
+		if got := domain.FormatRate(tt.input); got != tt.expected {
+			t.Errorf("FormatRate(%d) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRetryDelay caps the exponential backoff
+const maxRetryDelay = time.Hour
+
+// RetryPolicy decides whether and when failed downloads are started again
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries per item, including the
+	// first one; 1 or less disables retries
+	MaxAttempts      int         `json:"max_attempts"`
+	BaseDelaySeconds int         `json:"base_delay_seconds"`
+	RetryOn          []ErrorKind `json:"retry_on"`
+}
+
+// DefaultRetryPolicy retries network failures and rate limiting up to three
+// times in total
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:      3,
+		BaseDelaySeconds: 30,
+		RetryOn:          []ErrorKind{ErrorNetwork, ErrorRateLimited},
+	}
+}
+
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("max attempts must not be negative, got %d", p.MaxAttempts)
+	}
+	if p.BaseDelaySeconds < 0 {
+		return fmt.Errorf("retry delay must not be negative, got %d", p.BaseDelaySeconds)
+	}
+	for _, kind := range p.RetryOn {
+		if !kind.IsKnown() {
+			return fmt.Errorf("unknown error kind %q", kind)
+		}
+	}
+	return nil
+}
+
+// ShouldRetry reports whether an item that failed with the given kind after
+// the given number of attempts should be tried again
+func (p RetryPolicy) ShouldRetry(kind ErrorKind, attempts int) bool {
+	if attempts >= p.MaxAttempts {
+		return false
+	}
+	for _, k := range p.RetryOn {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the backoff before the next try after the given number of
+// attempts: the base delay, doubled for every further attempt
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := time.Duration(p.BaseDelaySeconds) * time.Second
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  domain.RetryPolicy
+		wantErr bool
+	}{
+		{name: "default", policy: domain.DefaultRetryPolicy()},
+		{name: "disabled", policy: domain.RetryPolicy{}},
+		{name: "negative attempts", policy: domain.RetryPolicy{MaxAttempts: -1}, wantErr: true},
+		{name: "negative delay", policy: domain.RetryPolicy{MaxAttempts: 2, BaseDelaySeconds: -5}, wantErr: true},
+		{name: "every kind", policy: domain.RetryPolicy{MaxAttempts: 2, RetryOn: []domain.ErrorKind{domain.ErrorUnknown, domain.ErrorDiskFull, domain.ErrorGeoBlocked}}},
+		{name: "unknown kind", policy: domain.RetryPolicy{MaxAttempts: 2, RetryOn: []domain.ErrorKind{"netwrok"}}, wantErr: true},
+		{name: "no error", policy: domain.RetryPolicy{MaxAttempts: 2, RetryOn: []domain.ErrorKind{domain.ErrorNone}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := domain.DefaultRetryPolicy()
+	tests := []struct {
+		name     string
+		kind     domain.ErrorKind
+		attempts int
+		expected bool
+	}{
+		{name: "network after first attempt", kind: domain.ErrorNetwork, attempts: 1, expected: true},
+		{name: "rate limited after second attempt", kind: domain.ErrorRateLimited, attempts: 2, expected: true},
+		{name: "attempts exhausted", kind: domain.ErrorNetwork, attempts: 3, expected: false},
+		{name: "kind not retried", kind: domain.ErrorUnknown, attempts: 1, expected: false},
+		{name: "ffmpeg missing not retried", kind: domain.ErrorFfmpegMissing, attempts: 1, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.ShouldRetry(tt.kind, tt.attempts); got != tt.expected {
+				t.Errorf("ShouldRetry(%q, %d) = %v, want %v", tt.kind, tt.attempts, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_DisabledNeverRetries(t *testing.T) {
+	policy := domain.RetryPolicy{MaxAttempts: 1, RetryOn: []domain.ErrorKind{domain.ErrorNetwork}}
+	if policy.ShouldRetry(domain.ErrorNetwork, 1) {
+		t.Error("expected no retry with a single attempt allowed")
+	}
+}
+
+func TestRetryPolicy_DelayDoubles(t *testing.T) {
+	policy := domain.RetryPolicy{BaseDelaySeconds: 10}
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", i+1, got, want)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayIsCapped(t *testing.T) {
+	policy := domain.RetryPolicy{BaseDelaySeconds: 600}
+	if got := policy.Delay(10); got != time.Hour {
+		t.Errorf("expected the delay to be capped at 1h, got %s", got)
+	}
+}
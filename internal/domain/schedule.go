@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DownloadWindow limits downloads to a daily range of local time, e.g.
+// 22:00 to 07:00. A range that ends before it starts runs past midnight,
+// and one that starts and ends at the same time covers the whole day.
+type DownloadWindow struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"` // "HH:MM"
+	End     string `json:"end"`   // "HH:MM"
+}
+
+func (w DownloadWindow) Validate() error {
+	if !w.Enabled {
+		return nil
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid window start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid window end: %w", err)
+	}
+	return nil
+}
+
+// parseClock returns the minutes since midnight of an "HH:MM" time
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsOpen reports whether downloads may run at t. Disabled or invalid
+// windows are always open.
+func (w DownloadWindow) IsOpen(t time.Time) bool {
+	if !w.Enabled {
+		return true
+	}
+	start, err1 := parseClock(w.Start)
+	end, err2 := parseClock(w.End)
+	if err1 != nil || err2 != nil || start == end {
+		return true
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// NextOpen returns when the window next opens after t, or t itself when it
+// is open
+func (w DownloadWindow) NextOpen(t time.Time) time.Time {
+	if w.IsOpen(t) {
+		return t
+	}
+	start, _ := parseClock(w.Start)
+	next := time.Date(t.Year(), t.Month(), t.Day(), start/60, start%60, 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SetStartAfter holds the item back until at; nil lets it start right away
+func (m *Media) SetStartAfter(at *time.Time) error {
+	return m.changeIdle(func() { m.StartAfter = at })
+}
+
+// CurrentStartAfter returns the time the item is held back until, if any
+func (m *Media) CurrentStartAfter() *time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.StartAfter
+}
+
+// StartDue reports whether the item's start time has come
+func (m *Media) StartDue(now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.StartAfter == nil || !now.Before(*m.StartAfter)
+}
@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+	"time"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 3, 10, hour, minute, 0, 0, time.Local)
+}
+
+func TestDownloadWindow_IsOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		window domain.DownloadWindow
+		time   time.Time
+		want   bool
+	}{
+		{"disabled", domain.DownloadWindow{Start: "22:00", End: "07:00"}, at(12, 0), true},
+		{"overnight, late evening", domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}, at(23, 30), true},
+		{"overnight, early morning", domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}, at(6, 59), true},
+		{"overnight, end is exclusive", domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}, at(7, 0), false},
+		{"overnight, daytime", domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}, at(12, 0), false},
+		{"same day, inside", domain.DownloadWindow{Enabled: true, Start: "09:00", End: "17:00"}, at(9, 0), true},
+		{"same day, outside", domain.DownloadWindow{Enabled: true, Start: "09:00", End: "17:00"}, at(18, 0), false},
+		{"whole day", domain.DownloadWindow{Enabled: true, Start: "00:00", End: "00:00"}, at(3, 0), true},
+		{"invalid", domain.DownloadWindow{Enabled: true, Start: "late", End: "07:00"}, at(12, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.IsOpen(tt.time); got != tt.want {
+				t.Errorf("IsOpen(%s) = %v, want %v", tt.time.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadWindow_NextOpen(t *testing.T) {
+	window := domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}
+
+	if got := window.NextOpen(at(12, 0)); !got.Equal(at(22, 0)) {
+		t.Errorf("expected 22:00 the same day, got %v", got)
+	}
+	if got := window.NextOpen(at(23, 0)); !got.Equal(at(23, 0)) {
+		t.Errorf("expected now while open, got %v", got)
+	}
+
+	morning := domain.DownloadWindow{Enabled: true, Start: "06:00", End: "08:00"}
+	if got := morning.NextOpen(at(9, 0)); !got.Equal(at(6, 0).AddDate(0, 0, 1)) {
+		t.Errorf("expected 06:00 the next day, got %v", got)
+	}
+}
+
+func TestDownloadWindow_Validate(t *testing.T) {
+	valid := []domain.DownloadWindow{
+		{},
+		{Start: "bad"}, // disabled windows aren't checked
+		{Enabled: true, Start: "22:00", End: "07:30"},
+	}
+	for _, w := range valid {
+		if err := w.Validate(); err != nil {
+			t.Errorf("Validate(%+v) unexpected error: %v", w, err)
+		}
+	}
+
+	invalid := []domain.DownloadWindow{
+		{Enabled: true, Start: "22:00"},
+		{Enabled: true, Start: "25:00", End: "07:00"},
+		{Enabled: true, Start: "10pm", End: "07:00"},
+	}
+	for _, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected an error", w)
+		}
+	}
+}
+
+func TestMedia_StartDue(t *testing.T) {
+	m := &domain.Media{}
+	if !m.StartDue(at(12, 0)) {
+		t.Error("expected an item without a start time to be due")
+	}
+
+	start := at(22, 0)
+	m.SetStartAfter(&start)
+	if m.StartDue(at(21, 59)) {
+		t.Error("expected the item not to be due before its start time")
+	}
+	if !m.StartDue(at(22, 0)) {
+		t.Error("expected the item to be due at its start time")
+	}
+}
@@ -5,10 +5,76 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
+// Setting holds the app settings. The UI changes them while downloads read
+// them, so they are changed through the Update methods and read through the
+// Current getters, which take mu.
 type Setting struct {
 	ParallelDownloads int `json:"parallel_downloads"`
+	// UseDownloadArchive skips videos already recorded in byto's download
+	// archive
+	UseDownloadArchive bool        `json:"use_download_archive"`
+	Retry              RetryPolicy `json:"retry"`
+	API                APISettings `json:"api"`
+	// RateLimit caps the combined download rate in bytes per second and is
+	// shared by the running downloads; 0 means unlimited
+	RateLimit int64           `json:"rate_limit"`
+	Network   NetworkSettings `json:"network"`
+	// DownloadWindow restricts downloads to certain hours of the day
+	DownloadWindow DownloadWindow `json:"download_window"`
+	// SiteLimits caps the parallel downloads of single sites
+	SiteLimits SiteLimits        `json:"site_limits"`
+	Clipboard  ClipboardSettings `json:"clipboard"`
+
+	mu sync.RWMutex
+}
+
+// MarshalJSON encodes the settings under the lock
+func (s *Setting) MarshalJSON() ([]byte, error) {
+	// setting has Setting's fields without its methods, so encoding it
+	// doesn't call MarshalJSON again
+	type setting Setting
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal((*setting)(s))
+}
+
+// CurrentNetwork returns the proxy and connection settings
+func (s *Setting) CurrentNetwork() NetworkSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Network
+}
+
+// CurrentDownloadWindow returns the hours downloads may run in
+func (s *Setting) CurrentDownloadWindow() DownloadWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.DownloadWindow
+}
+
+// CurrentRetry returns the retry policy
+func (s *Setting) CurrentRetry() RetryPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Retry
+}
+
+// CurrentClipboard returns the clipboard watcher settings
+func (s *Setting) CurrentClipboard() ClipboardSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Clipboard
+}
+
+// CurrentDownloadArchive reports whether downloads use the archive
+func (s *Setting) CurrentDownloadArchive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.UseDownloadArchive
 }
 
 func getSettingsFilePath() string {
@@ -43,6 +109,8 @@ func NewSetting() *Setting {
 
 	return &Setting{
 		ParallelDownloads: 1,
+		Retry:             DefaultRetryPolicy(),
+		API:               DefaultAPISettings(),
 	}
 }
 
@@ -57,7 +125,8 @@ func loadSettings() *Setting {
 		return nil
 	}
 
-	var settings Setting
+	// Files saved before retries or the API existed get their defaults
+	settings := Setting{Retry: DefaultRetryPolicy(), API: DefaultAPISettings()}
 	if err := json.Unmarshal(data, &settings); err != nil {
 		log.Printf("Error parsing settings file: %v", err)
 		return nil
@@ -75,7 +144,12 @@ func (s *Setting) Save() error {
 		return err
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	// The network settings may hold a proxy password, so keep the file
+	// private to the user, also when it was created before
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Chmod(filePath, 0600); err != nil {
 		return err
 	}
 
@@ -84,5 +158,55 @@ func (s *Setting) Save() error {
 }
 
 func (s *Setting) Update(parallelDownloads int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.ParallelDownloads = parallelDownloads
 }
+
+func (s *Setting) UpdateDownloadArchive(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.UseDownloadArchive = enabled
+}
+
+func (s *Setting) UpdateRetryPolicy(policy RetryPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Retry = policy
+}
+
+func (s *Setting) UpdateAPI(api APISettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.API = api
+}
+
+func (s *Setting) UpdateRateLimit(bytesPerSecond int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RateLimit = bytesPerSecond
+}
+
+func (s *Setting) UpdateNetwork(network NetworkSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Network = network
+}
+
+func (s *Setting) UpdateDownloadWindow(window DownloadWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DownloadWindow = window
+}
+
+func (s *Setting) UpdateSiteLimits(limits SiteLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SiteLimits = limits
+}
+
+func (s *Setting) UpdateClipboard(clipboard ClipboardSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Clipboard = clipboard
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -121,6 +122,71 @@ func TestNewSetting_ExtraFields_IgnoredGracefully(t *testing.T) {
 	}
 }
 
+func TestNewSetting_FileWithoutRetry_UsesDefaultPolicy(t *testing.T) {
+	configDir, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+
+	writeSettingsFile(t, configDir, []byte(`{"parallel_downloads": 2}`))
+
+	s := domain.NewSetting()
+	if s.Retry.MaxAttempts != domain.DefaultRetryPolicy().MaxAttempts {
+		t.Errorf("expected the default retry policy, got %+v", s.Retry)
+	}
+}
+
+func TestNewSetting_RetryPolicyLoadedFromFile(t *testing.T) {
+	configDir, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+
+	data := []byte(`{"parallel_downloads": 2, "retry": {"max_attempts": 5, "base_delay_seconds": 10, "retry_on": ["network"]}}`)
+	writeSettingsFile(t, configDir, data)
+
+	s := domain.NewSetting()
+	if s.Retry.MaxAttempts != 5 || s.Retry.BaseDelaySeconds != 10 {
+		t.Errorf("unexpected retry policy %+v", s.Retry)
+	}
+	if len(s.Retry.RetryOn) != 1 || s.Retry.RetryOn[0] != domain.ErrorNetwork {
+		t.Errorf("expected retry on network only, got %v", s.Retry.RetryOn)
+	}
+}
+
+func TestNewSetting_FileWithoutAPI_UsesDefaultPort(t *testing.T) {
+	configDir, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+
+	writeSettingsFile(t, configDir, []byte(`{"parallel_downloads": 2}`))
+
+	s := domain.NewSetting()
+	if s.API.Enabled {
+		t.Error("expected the API to be disabled")
+	}
+	if s.API.Port != domain.DefaultAPIPort {
+		t.Errorf("expected port %d, got %d", domain.DefaultAPIPort, s.API.Port)
+	}
+}
+
+func TestAPISettings_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		api     domain.APISettings
+		wantErr bool
+	}{
+		{"disabled without token", domain.APISettings{Port: 7979}, false},
+		{"enabled with token", domain.APISettings{Enabled: true, Port: 7979, Token: "secret"}, false},
+		{"enabled without token", domain.APISettings{Enabled: true, Port: 7979}, true},
+		{"port zero", domain.APISettings{Port: 0}, true},
+		{"port too high", domain.APISettings{Port: 70000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.api.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestNewSetting_NegativeParallelDownloads(t *testing.T) {
 	configDir, cleanup := setupTempConfigDir(t)
 	defer cleanup()
@@ -371,6 +437,44 @@ func TestUpdateSaveAndLoad_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestUpdateDownloadArchive_SaveAndLoad_RoundTrip(t *testing.T) {
+	_, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+
+	s := domain.NewSetting()
+	if s.UseDownloadArchive {
+		t.Fatal("expected the download archive to be off by default")
+	}
+	s.UpdateDownloadArchive(true)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded := domain.NewSetting()
+	if !loaded.UseDownloadArchive {
+		t.Error("expected UseDownloadArchive=true after loading")
+	}
+}
+
+func TestUpdateSiteLimits_SaveAndLoad_RoundTrip(t *testing.T) {
+	_, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+
+	s := domain.NewSetting()
+	if len(s.SiteLimits) != 0 {
+		t.Fatal("expected no site limits by default")
+	}
+	s.UpdateSiteLimits(domain.SiteLimits{{Site: "youtube.com", MaxParallel: 2}})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded := domain.NewSetting()
+	if len(loaded.SiteLimits) != 1 || loaded.SiteLimits[0] != (domain.SiteLimit{Site: "youtube.com", MaxParallel: 2}) {
+		t.Errorf("expected the youtube.com limit after loading, got %+v", loaded.SiteLimits)
+	}
+}
+
 func TestMultipleSaveAndLoad_LastWins(t *testing.T) {
 	_, cleanup := setupTempConfigDir(t)
 	defer cleanup()
@@ -394,7 +498,7 @@ func TestMultipleSaveAndLoad_LastWins(t *testing.T) {
 // --- JSON serialization tests ---
 
 func TestSetting_JSONTags(t *testing.T) {
-	s := domain.Setting{ParallelDownloads: 4}
+	s := &domain.Setting{ParallelDownloads: 4}
 	data, err := json.Marshal(s)
 	if err != nil {
 		t.Fatalf("Marshal error: %v", err)
@@ -512,3 +616,23 @@ func TestNewSetting_WrongTypeJSON(t *testing.T) {
 		t.Errorf("expected default ParallelDownloads=1, got %d", s.ParallelDownloads)
 	}
 }
+
+func TestSave_KeepsFilePrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	configDir, cleanup := setupTempConfigDir(t)
+	defer cleanup()
+	filePath := writeSettingsFile(t, configDir, []byte(`{}`))
+
+	if err := domain.NewSetting().Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected mode 0600, got %o", mode)
+	}
+}
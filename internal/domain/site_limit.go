@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SiteLimit caps how many downloads of one site run at the same time, on
+// top of the global number of parallel downloads. Site is either a domain,
+// such as "youtube.com", which also covers its subdomains, or a yt-dlp
+// extractor name, such as "youtube".
+type SiteLimit struct {
+	Site        string `json:"site"`
+	MaxParallel int    `json:"max_parallel"`
+}
+
+// SiteLimits holds the per-site caps; sites without one are unlimited
+type SiteLimits []SiteLimit
+
+func (l SiteLimits) Validate() error {
+	seen := make(map[string]bool, len(l))
+	for _, limit := range l {
+		site := normalizeSite(limit.Site)
+		if site == "" {
+			return errors.New("site must not be empty")
+		}
+		if limit.MaxParallel < 1 {
+			return fmt.Errorf("limit for %s must be at least 1, got %d", site, limit.MaxParallel)
+		}
+		if seen[site] {
+			return fmt.Errorf("site %s is listed more than once", site)
+		}
+		seen[site] = true
+	}
+	return nil
+}
+
+// For returns the first limit matching the item, if any
+func (l SiteLimits) For(m *Media) (SiteLimit, bool) {
+	for _, limit := range l {
+		if limit.Matches(m) {
+			return limit, true
+		}
+	}
+	return SiteLimit{}, false
+}
+
+// Matches reports whether the limit applies to the item. A domain matches
+// the host of the item's URL or any subdomain of it; an extractor name
+// matches the extractor found by the probe step, so "youtube" also covers
+// "youtube:tab".
+func (l SiteLimit) Matches(m *Media) bool {
+	site := normalizeSite(l.Site)
+	if site == "" {
+		return false
+	}
+
+	if strings.Contains(site, ".") {
+		u, err := url.Parse(m.CurrentURL())
+		if err != nil {
+			return false
+		}
+		return HostMatches(u.Hostname(), site)
+	}
+
+	extractor := strings.ToLower(m.CurrentExtractor())
+	return extractor == site || strings.HasPrefix(extractor, site+":")
+}
@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"testing"
+)
+
+func TestSiteLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  domain.SiteLimits
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"domain and extractor", domain.SiteLimits{{Site: "youtube.com", MaxParallel: 2}, {Site: "vimeo", MaxParallel: 1}}, false},
+		{"empty site", domain.SiteLimits{{Site: " ", MaxParallel: 2}}, true},
+		{"zero", domain.SiteLimits{{Site: "youtube.com", MaxParallel: 0}}, true},
+		{"duplicate", domain.SiteLimits{{Site: "youtube.com", MaxParallel: 2}, {Site: "YouTube.com", MaxParallel: 3}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSiteLimit_Matches(t *testing.T) {
+	tests := []struct {
+		site  string
+		media *domain.Media
+		want  bool
+	}{
+		{"youtube.com", &domain.Media{URL: "https://youtube.com/watch?v=1"}, true},
+		{"youtube.com", &domain.Media{URL: "https://www.youtube.com/watch?v=1"}, true},
+		{" .YouTube.com", &domain.Media{URL: "https://m.youtube.com/watch?v=1"}, true},
+		{"youtube.com", &domain.Media{URL: "https://notyoutube.com/watch?v=1"}, false},
+		{"youtube.com", &domain.Media{URL: "https://youtu.be/1", Extractor: "youtube"}, false},
+		{"youtube", &domain.Media{URL: "https://youtu.be/1", Extractor: "youtube"}, true},
+		{"youtube", &domain.Media{URL: "https://youtube.com/@x", Extractor: "youtube:tab"}, true},
+		{"youtube", &domain.Media{URL: "https://youtube.com/watch?v=1"}, false},
+		{"vimeo", &domain.Media{URL: "https://vimeo.com/1", Extractor: "vimeo"}, true},
+	}
+	for _, tt := range tests {
+		limit := domain.SiteLimit{Site: tt.site, MaxParallel: 1}
+		if got := limit.Matches(tt.media); got != tt.want {
+			t.Errorf("%q Matches(%q, %q) = %v, want %v", tt.site, tt.media.URL, tt.media.Extractor, got, tt.want)
+		}
+	}
+}
+
+func TestSiteLimits_For(t *testing.T) {
+	limits := domain.SiteLimits{{Site: "youtube.com", MaxParallel: 2}, {Site: "youtube", MaxParallel: 1}}
+
+	limit, ok := limits.For(&domain.Media{URL: "https://www.youtube.com/watch?v=1", Extractor: "youtube"})
+	if !ok || limit.Site != "youtube.com" {
+		t.Errorf("expected the first matching limit, got %+v, %v", limit, ok)
+	}
+	if _, ok := limits.For(&domain.Media{URL: "https://vimeo.com/1", Extractor: "vimeo"}); ok {
+		t.Error("expected no limit for an unlisted site")
+	}
+}
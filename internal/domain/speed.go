@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+// speedSmoothing is the weight of the newest sample in the moving average
+const speedSmoothing = 0.3
+
+// minSpeedSampleInterval keeps bursts of progress lines from producing
+// wild instantaneous speeds
+const minSpeedSampleInterval = 500 * time.Millisecond
+
+// SpeedEstimator computes a smoothed download speed from downloaded byte
+// counts. It is used for fragmented (HLS/DASH) downloads where yt-dlp
+// doesn't report a speed.
+type SpeedEstimator struct {
+	lastBytes int64
+	lastTime  time.Time
+	speed     float64
+	mu        sync.Mutex
+}
+
+// Update records the bytes downloaded so far at the given time and returns
+// the smoothed speed in bytes per second. A drop in bytes means a new file
+// started, so sampling starts over while keeping the previous speed.
+func (s *SpeedEstimator) Update(downloaded int64, now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastTime.IsZero() || downloaded < s.lastBytes {
+		s.lastBytes = downloaded
+		s.lastTime = now
+		return s.speed
+	}
+
+	elapsed := now.Sub(s.lastTime)
+	if elapsed < minSpeedSampleInterval {
+		return s.speed
+	}
+
+	sample := float64(downloaded-s.lastBytes) / elapsed.Seconds()
+	if s.speed == 0 {
+		s.speed = sample
+	} else {
+		s.speed = speedSmoothing*sample + (1-speedSmoothing)*s.speed
+	}
+	s.lastBytes = downloaded
+	s.lastTime = now
+	return s.speed
+}
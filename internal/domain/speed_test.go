@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpeedEstimator_FirstSampleHasNoSpeed(t *testing.T) {
+	var s domain.SpeedEstimator
+	if speed := s.Update(1000, time.Now()); speed != 0 {
+		t.Errorf("expected 0 before a second sample, got %f", speed)
+	}
+}
+
+func TestSpeedEstimator_MeasuresSpeed(t *testing.T) {
+	var s domain.SpeedEstimator
+	start := time.Now()
+	s.Update(0, start)
+	speed := s.Update(2000, start.Add(2*time.Second))
+	if speed != 1000 {
+		t.Errorf("expected 1000 B/s, got %f", speed)
+	}
+}
+
+func TestSpeedEstimator_SmoothsSamples(t *testing.T) {
+	var s domain.SpeedEstimator
+	start := time.Now()
+	s.Update(0, start)
+	s.Update(1000, start.Add(time.Second))            // 1000 B/s
+	speed := s.Update(4000, start.Add(2*time.Second)) // 3000 B/s sample
+
+	// 0.3*3000 + 0.7*1000
+	if math.Abs(speed-1600) > 0.001 {
+		t.Errorf("expected smoothed 1600 B/s, got %f", speed)
+	}
+}
+
+func TestSpeedEstimator_IgnoresSamplesTooCloseTogether(t *testing.T) {
+	var s domain.SpeedEstimator
+	start := time.Now()
+	s.Update(0, start)
+	s.Update(1000, start.Add(time.Second))
+	speed := s.Update(100000, start.Add(time.Second+10*time.Millisecond))
+	if speed != 1000 {
+		t.Errorf("expected the burst to be ignored, got %f", speed)
+	}
+}
+
+func TestSpeedEstimator_NewFileKeepsSpeed(t *testing.T) {
+	var s domain.SpeedEstimator
+	start := time.Now()
+	s.Update(0, start)
+	s.Update(1000, start.Add(time.Second))
+	if speed := s.Update(0, start.Add(2*time.Second)); speed != 1000 {
+		t.Errorf("expected the previous speed to be kept, got %f", speed)
+	}
+	if speed := s.Update(3000, start.Add(3*time.Second)); math.Abs(speed-1600) > 0.001 {
+		t.Errorf("expected sampling to restart from the new file, got %f", speed)
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type SubtitleSource string
+
+const (
+	SubtitleManual SubtitleSource = "manual"
+	SubtitleAuto   SubtitleSource = "auto"
+	SubtitleBoth   SubtitleSource = "both"
+)
+
+type SubtitleFormat string
+
+const (
+	SubtitleSRT SubtitleFormat = "srt"
+	SubtitleVTT SubtitleFormat = "vtt"
+	SubtitleASS SubtitleFormat = "ass"
+)
+
+// DefaultSubtitleLanguage is downloaded when no language is picked. The app
+// has no interface language setting, so it's English rather than every
+// language a site offers, which can be dozens of requests for one video.
+const DefaultSubtitleLanguage = "en"
+
+var subtitleLangPattern = regexp.MustCompile(`^[A-Za-z0-9_.*\-]+$`)
+
+// SubtitleOptions controls which subtitles are downloaded with a media item
+// and whether they are embedded or written next to it.
+type SubtitleOptions struct {
+	Enabled   bool           `json:"enabled"`
+	Languages []string       `json:"languages"` // empty means DefaultSubtitleLanguage
+	Source    SubtitleSource `json:"source"`    // manual, auto-generated or both
+	Format    SubtitleFormat `json:"format"`
+	Embed     bool           `json:"embed"` // false writes a sidecar file
+}
+
+func (s SubtitleOptions) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	for _, lang := range s.Languages {
+		if !subtitleLangPattern.MatchString(lang) {
+			return fmt.Errorf("invalid subtitle language: %q", lang)
+		}
+	}
+	switch s.Source {
+	case "", SubtitleManual, SubtitleAuto, SubtitleBoth:
+	default:
+		return fmt.Errorf("unsupported subtitle source: %q", s.Source)
+	}
+	switch s.Format {
+	case "", SubtitleSRT, SubtitleVTT, SubtitleASS:
+	default:
+		return fmt.Errorf("unsupported subtitle format: %q", s.Format)
+	}
+	return nil
+}
+
+// ResolvedLanguages returns the languages to download, falling back to
+// DefaultSubtitleLanguage
+func (s SubtitleOptions) ResolvedLanguages() []string {
+	if len(s.Languages) > 0 {
+		return s.Languages
+	}
+	return []string{DefaultSubtitleLanguage}
+}
+
+// Describe returns a short human-readable summary for the item logs
+func (s SubtitleOptions) Describe() string {
+	if !s.Enabled {
+		return "off"
+	}
+	langs := strings.Join(s.ResolvedLanguages(), ", ")
+	source := s.Source
+	if source == "" {
+		source = SubtitleManual
+	}
+	format := s.Format
+	if format == "" {
+		format = SubtitleSRT
+	}
+	mode := "sidecar file"
+	if s.Embed {
+		mode = "embedded"
+	}
+	return fmt.Sprintf("%s (%s, %s, %s)", langs, source, format, mode)
+}
@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"byto/internal/domain"
+	"encoding/json"
+	"testing"
+)
+
+func TestSubtitleOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     domain.SubtitleOptions
+		expectError bool
+	}{
+		{name: "disabled ignores bad values", options: domain.SubtitleOptions{Languages: []string{"bad lang"}, Format: "xyz"}},
+		{name: "enabled defaults", options: domain.SubtitleOptions{Enabled: true}},
+		{name: "languages and regex", options: domain.SubtitleOptions{Enabled: true, Languages: []string{"en", "pt-BR", "en.*"}}},
+		{name: "language with space", options: domain.SubtitleOptions{Enabled: true, Languages: []string{"en us"}}, expectError: true},
+		{name: "language with comma", options: domain.SubtitleOptions{Enabled: true, Languages: []string{"en,fr"}}, expectError: true},
+		{name: "auto source", options: domain.SubtitleOptions{Enabled: true, Source: domain.SubtitleAuto}},
+		{name: "unknown source", options: domain.SubtitleOptions{Enabled: true, Source: "robot"}, expectError: true},
+		{name: "ass format", options: domain.SubtitleOptions{Enabled: true, Format: domain.SubtitleASS}},
+		{name: "unknown format", options: domain.SubtitleOptions{Enabled: true, Format: "sub"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubtitleOptions_Describe(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  domain.SubtitleOptions
+		expected string
+	}{
+		{name: "disabled", options: domain.SubtitleOptions{}, expected: "off"},
+		{name: "defaults", options: domain.SubtitleOptions{Enabled: true}, expected: "en (manual, srt, sidecar file)"},
+		{
+			name:     "explicit",
+			options:  domain.SubtitleOptions{Enabled: true, Languages: []string{"en", "ar"}, Source: domain.SubtitleBoth, Format: domain.SubtitleVTT, Embed: true},
+			expected: "en, ar (both, vtt, embedded)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Describe(); got != tt.expected {
+				t.Errorf("Describe() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMediaDefaults_UpdateSubtitles(t *testing.T) {
+	m := &domain.MediaDefaults{}
+	opts := domain.SubtitleOptions{Enabled: true, Languages: []string{"en"}, Embed: true}
+	m.UpdateSubtitles(opts)
+	if !m.Subtitles.Enabled || len(m.Subtitles.Languages) != 1 || !m.Subtitles.Embed {
+		t.Errorf("subtitles not updated: %+v", m.Subtitles)
+	}
+}
+
+func TestMediaDefaults_SubtitlesJSONRoundTrip(t *testing.T) {
+	m := domain.MediaDefaults{Subtitles: domain.SubtitleOptions{Enabled: true, Languages: []string{"fr"}, Format: domain.SubtitleASS}}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var loaded domain.MediaDefaults
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !loaded.Subtitles.Enabled || loaded.Subtitles.Languages[0] != "fr" || loaded.Subtitles.Format != domain.SubtitleASS {
+		t.Errorf("unexpected subtitles after round trip: %+v", loaded.Subtitles)
+	}
+}
@@ -0,0 +1,174 @@
+package downloader
+
+import (
+	"byto/internal/domain"
+	"sync"
+	"time"
+)
+
+// rebalanceThreshold is how far, as a fraction, a running download's share
+// may drift from its fair share before it is restarted with the new one.
+// yt-dlp can't change its rate limit while running, so every rebalance
+// briefly interrupts the download.
+const rebalanceThreshold = 0.2
+
+// DefaultRebalanceDelay is how long the shares have to stay unchanged
+// before running downloads are restarted with them, so a burst of starts
+// and finishes causes one restart rather than one per change. Every restart
+// repeats yt-dlp's extraction, which counts against the site's rate limits.
+const DefaultRebalanceDelay = 5 * time.Second
+
+// Bandwidth splits the global rate limit evenly across the running
+// downloads that don't have a limit of their own, and restarts them when
+// their share changes as downloads start and finish. Downloads that are
+// post-processing are never restarted, as that would interrupt ffmpeg.
+type Bandwidth struct {
+	limit int64
+	slots map[*domain.Media]*bandwidthSlot
+	delay time.Duration
+	timer *time.Timer
+	mu    sync.Mutex
+}
+
+type bandwidthSlot struct {
+	applied int64
+	restart func()
+}
+
+func NewBandwidth(limit int64) *Bandwidth {
+	return &Bandwidth{
+		limit: limit,
+		slots: make(map[*domain.Media]*bandwidthSlot),
+		delay: DefaultRebalanceDelay,
+	}
+}
+
+// SetRebalanceDelay changes how long shares have to settle before running
+// downloads are restarted; 0 restarts them right away
+func (b *Bandwidth) SetRebalanceDelay(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delay = delay
+}
+
+// Limit returns the global rate limit in bytes per second
+func (b *Bandwidth) Limit() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
+
+// SetLimit changes the global rate limit and rebalances running downloads
+func (b *Bandwidth) SetLimit(limit int64) {
+	b.mu.Lock()
+	b.limit = limit
+	b.mu.Unlock()
+	b.scheduleRebalance()
+}
+
+// Acquire registers a running download and returns the rate it should be
+// started with. restart is called when the download's share changes enough
+// that it should be started again with a new one. Calling Acquire again for
+// a registered download just takes the current share.
+func (b *Bandwidth) Acquire(m *domain.Media, restart func()) int64 {
+	b.mu.Lock()
+	slot, ok := b.slots[m]
+	if !ok {
+		slot = &bandwidthSlot{}
+		b.slots[m] = slot
+	}
+	slot.restart = restart
+	slot.applied = b.share()
+	applied := slot.applied
+	b.mu.Unlock()
+
+	if !ok {
+		b.scheduleRebalance()
+	}
+	return applied
+}
+
+// Release removes a finished download and hands its share to the others
+func (b *Bandwidth) Release(m *domain.Media) {
+	b.mu.Lock()
+	if _, ok := b.slots[m]; !ok {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.slots, m)
+	b.mu.Unlock()
+	b.scheduleRebalance()
+}
+
+// scheduleRebalance rebalances once the shares have stayed unchanged for
+// the rebalance delay
+func (b *Bandwidth) scheduleRebalance() {
+	b.mu.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.delay > 0 {
+		b.timer = time.AfterFunc(b.delay, b.rebalanceNow)
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	b.rebalanceNow()
+}
+
+func (b *Bandwidth) rebalanceNow() {
+	b.mu.Lock()
+	restarts := b.rebalance()
+	b.mu.Unlock()
+
+	for _, restart := range restarts {
+		restart()
+	}
+}
+
+// share returns the fair share of each registered download. Callers must
+// hold b.mu.
+func (b *Bandwidth) share() int64 {
+	if b.limit <= 0 {
+		return 0
+	}
+	if len(b.slots) == 0 {
+		return b.limit
+	}
+	share := b.limit / int64(len(b.slots))
+	if share < 1 {
+		share = 1
+	}
+	return share
+}
+
+// rebalance returns the restart functions of the downloads whose applied
+// share is too far off. They are marked with the new share right away so
+// one change triggers one restart. Callers must hold b.mu and call the
+// functions after releasing it.
+func (b *Bandwidth) rebalance() []func() {
+	share := b.share()
+	var restarts []func()
+	for m, slot := range b.slots {
+		if !needsRebalance(slot.applied, share) || m.CurrentStatus() == domain.Processing {
+			continue
+		}
+		slot.applied = share
+		if slot.restart != nil {
+			restarts = append(restarts, slot.restart)
+		}
+	}
+	return restarts
+}
+
+func needsRebalance(applied, share int64) bool {
+	if applied == share {
+		return false
+	}
+	if applied == 0 || share == 0 {
+		return true
+	}
+	diff := float64(share-applied) / float64(applied)
+	return diff > rebalanceThreshold || diff < -rebalanceThreshold
+}
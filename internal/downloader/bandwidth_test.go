@@ -0,0 +1,135 @@
+package downloader_test
+
+import (
+	"byto/internal/domain"
+	"byto/internal/downloader"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// restartCounter counts the restarts requested for a download
+type restartCounter struct {
+	n atomic.Int32
+}
+
+func (r *restartCounter) restart() {
+	r.n.Add(1)
+}
+
+func (r *restartCounter) count() int {
+	return int(r.n.Load())
+}
+
+// newBandwidth returns a bandwidth that rebalances right away
+func newBandwidth(limit int64) *downloader.Bandwidth {
+	b := downloader.NewBandwidth(limit)
+	b.SetRebalanceDelay(0)
+	return b
+}
+
+func TestBandwidth_Unlimited(t *testing.T) {
+	b := newBandwidth(0)
+	var r restartCounter
+	if rate := b.Acquire(&domain.Media{ID: "1"}, r.restart); rate != 0 {
+		t.Errorf("expected no limit, got %d", rate)
+	}
+	b.Acquire(&domain.Media{ID: "2"}, r.restart)
+	if r.count() != 0 {
+		t.Errorf("expected no restarts without a limit, got %d", r.count())
+	}
+}
+
+func TestBandwidth_SplitsAndRebalances(t *testing.T) {
+	b := newBandwidth(900)
+	first, second, third := &domain.Media{ID: "1"}, &domain.Media{ID: "2"}, &domain.Media{ID: "3"}
+	var r1, r2, r3 restartCounter
+
+	if rate := b.Acquire(first, r1.restart); rate != 900 {
+		t.Errorf("expected the whole limit for a single download, got %d", rate)
+	}
+	if rate := b.Acquire(second, r2.restart); rate != 450 {
+		t.Errorf("expected half the limit, got %d", rate)
+	}
+	if r1.count() != 1 || r2.count() != 0 {
+		t.Errorf("expected only the first download to restart, got %d and %d", r1.count(), r2.count())
+	}
+
+	// The first download comes back after its restart
+	if rate := b.Acquire(first, r1.restart); rate != 450 {
+		t.Errorf("expected half the limit after the restart, got %d", rate)
+	}
+	if r1.count() != 1 {
+		t.Errorf("expected re-acquiring not to restart again, got %d", r1.count())
+	}
+
+	if rate := b.Acquire(third, r3.restart); rate != 300 {
+		t.Errorf("expected a third of the limit, got %d", rate)
+	}
+	if r1.count() != 2 || r2.count() != 1 || r3.count() != 0 {
+		t.Errorf("unexpected restarts %d, %d, %d", r1.count(), r2.count(), r3.count())
+	}
+
+	b.Release(third)
+	if r1.count() != 3 || r2.count() != 2 {
+		t.Errorf("expected the others to restart when one finished, got %d and %d", r1.count(), r2.count())
+	}
+	b.Release(third)
+	if r1.count() != 3 || r2.count() != 2 {
+		t.Errorf("expected releasing twice to do nothing, got %d and %d", r1.count(), r2.count())
+	}
+}
+
+func TestBandwidth_SmallChangesDontRestart(t *testing.T) {
+	b := newBandwidth(1000)
+	var r restartCounter
+	b.Acquire(&domain.Media{ID: "1"}, r.restart)
+
+	b.SetLimit(1100)
+	if r.count() != 0 {
+		t.Errorf("expected no restart for a 10%% change, got %d", r.count())
+	}
+	b.SetLimit(2000)
+	if r.count() != 1 {
+		t.Errorf("expected a restart when the limit doubles, got %d", r.count())
+	}
+	b.SetLimit(0)
+	if r.count() != 2 {
+		t.Errorf("expected a restart when the limit is lifted, got %d", r.count())
+	}
+	if b.Limit() != 0 {
+		t.Errorf("expected no limit, got %d", b.Limit())
+	}
+}
+
+func TestBandwidth_ProcessingIsNotRestarted(t *testing.T) {
+	b := newBandwidth(900)
+	first := &domain.Media{ID: "1", Status: domain.Processing}
+	var r1, r2 restartCounter
+	b.Acquire(first, r1.restart)
+	b.Acquire(&domain.Media{ID: "2", Status: domain.InProgress}, r2.restart)
+	if r1.count() != 0 {
+		t.Errorf("expected a post-processing download not to restart, got %d", r1.count())
+	}
+}
+
+func TestBandwidth_DebouncesRestarts(t *testing.T) {
+	b := downloader.NewBandwidth(900)
+	b.SetRebalanceDelay(50 * time.Millisecond)
+	var r1, r2, r3 restartCounter
+	b.Acquire(&domain.Media{ID: "1"}, r1.restart)
+	b.Acquire(&domain.Media{ID: "2"}, r2.restart)
+	b.Acquire(&domain.Media{ID: "3"}, r3.restart)
+	if r1.count() != 0 {
+		t.Fatalf("expected no restart before the delay, got %d", r1.count())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for r1.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if r1.count() != 1 || r2.count() != 1 || r3.count() != 0 {
+		t.Errorf("expected one restart per outdated download, got %d, %d, %d", r1.count(), r2.count(), r3.count())
+	}
+}
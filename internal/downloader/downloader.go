@@ -0,0 +1,245 @@
+package downloader
+
+import (
+	"byto/internal/archive"
+	"byto/internal/auth"
+	"byto/internal/builder"
+	"byto/internal/command"
+	"byto/internal/domain"
+	"byto/internal/updater"
+	"context"
+	"fmt"
+	"log"
+)
+
+// Downloader runs the download of a single media item. It is the core shared
+// by the desktop app and the command-line interface.
+type Downloader struct {
+	Settings *domain.Setting
+	Updater  *updater.Updater
+	Archive  *archive.Archive
+	Profiles *auth.Store
+	// Bandwidth shares the global rate limit between running downloads
+	Bandwidth *Bandwidth
+}
+
+func NewDownloader(settings *domain.Setting, updater *updater.Updater, archive *archive.Archive, profiles *auth.Store) *Downloader {
+	var limit int64
+	if settings != nil {
+		limit = settings.RateLimit
+	}
+	return &Downloader{
+		Settings:  settings,
+		Updater:   updater,
+		Archive:   archive,
+		Profiles:  profiles,
+		Bandwidth: NewBandwidth(limit),
+	}
+}
+
+// AuthProfile returns the authentication profile to use for a media item,
+// see auth.Store.Resolve. Profiles that can't be loaded are logged and
+// treated as absent.
+func (d *Downloader) AuthProfile(m *domain.Media) (domain.AuthProfile, bool) {
+	if d.Profiles == nil {
+		return domain.AuthProfile{}, false
+	}
+	profile, ok, err := d.Profiles.Resolve(m.AuthProfileID, m.URL)
+	if err != nil {
+		log.Printf("Error loading auth profile for %s: %v", m.URL, err)
+		m.AppendLog(fmt.Sprintf("[byto] Could not load the authentication profile: %v", err))
+		return domain.AuthProfile{}, false
+	}
+	return profile, ok
+}
+
+// newBaseBuilder starts a builder with the options every yt-dlp call shares
+func (d *Downloader) newBaseBuilder() *builder.YTDLPBuilder {
+	b := builder.NewYTDLPBuilder()
+	if d.Settings != nil {
+		b = b.Network(d.Settings.CurrentNetwork())
+	}
+	return b
+}
+
+// NewProbeBuilder builds the arguments for probing a media item's metadata,
+// signed in with its authentication profile so private videos can be
+// probed too. Items not added as playlists probe only their video.
+func (d *Downloader) NewProbeBuilder(m *domain.Media) *builder.YTDLPBuilder {
+	b := d.newBaseBuilder().URL(m.URL)
+	if !m.IsPlaylist {
+		b = b.NoPlaylist()
+	}
+	if profile, ok := d.AuthProfile(m); ok {
+		b = b.Auth(profile)
+	}
+	return b
+}
+
+// NewBuilder builds the yt-dlp arguments for a media item from its own
+// FilePath, Quality and format options, signed in with its authentication
+// profile.
+func (d *Downloader) NewBuilder(m *domain.Media) *builder.YTDLPBuilder {
+	profile, signedIn := d.AuthProfile(m)
+	return d.newBuilder(m, profile, signedIn)
+}
+
+// newBuilder builds the yt-dlp arguments for a media item with an already
+// resolved authentication profile
+func (d *Downloader) newBuilder(m *domain.Media, profile domain.AuthProfile, signedIn bool) *builder.YTDLPBuilder {
+	b := d.newBaseBuilder().
+		URL(m.URL).
+		OutputTemplate(m.FilePath, m.ResolvedOutputTemplate()).
+		SafeFilenames()
+
+	switch {
+	case m.OnlyAudio && m.Format.FormatID == "":
+		b = b.Audio()
+	case m.OnlyAudio:
+		// The picked format is the audio, don't merge another one in
+		b = b.FormatID(m.Format.FormatID)
+	case !m.Format.IsZero():
+		format := m.Format
+		if format.FormatID == "" && format.MaxHeight == 0 {
+			format.MaxHeight = m.Quality.Height()
+		}
+		b = b.Format(format)
+	default:
+		b = b.Video(m.Quality)
+	}
+
+	if m.IsPlaylist {
+		b = b.Playlist(m.PlaylistSelection)
+	} else {
+		b = b.NoPlaylist()
+	}
+	b = b.Subtitles(m.Subtitles)
+	if m.OnlyAudio {
+		if m.Audio.Codec != domain.AudioOriginal {
+			b = b.AudioProfile(m.Audio)
+		} else if m.Embed.Thumbnail || m.Embed.Metadata {
+			// Cover art and tags can't be written into the webm audio
+			// most sites serve, so remux to a standalone audio file
+			b = b.ExtractAudio()
+		}
+	}
+	b = b.Embed(m.Embed)
+	if d.usesArchive() {
+		b = b.DownloadArchive(d.Archive.FilePath())
+	}
+	if signedIn {
+		b = b.Auth(profile)
+	}
+	return b.RateLimit(m.RateLimit)
+}
+
+// usesArchive reports whether downloads record themselves in the archive
+func (d *Downloader) usesArchive() bool {
+	return d.Settings != nil && d.Settings.CurrentDownloadArchive() && d.Archive != nil
+}
+
+// Run executes one download attempt for a media item and records the
+// outcome on it. Items that were paused, scheduled or are being retried
+// continue from their partial files instead of starting over. It returns
+// context.Canceled when the item was paused.
+func (d *Downloader) Run(m *domain.Media) error {
+	_, retrying := m.RetryScheduledAt()
+	resume := m.Status == domain.Paused || m.Status == domain.Scheduled || retrying
+	m.BeginAttempt()
+	m.SetStatus(domain.InProgress)
+	log.Printf("Processing item: %s", m.URL)
+	if m.Subtitles.Enabled {
+		m.AppendLog("[byto] Subtitles: " + m.Subtitles.Describe())
+	}
+
+	var ffmpegPath string
+	if m.NeedsFfmpeg() {
+		ffmpeg := d.Updater.CheckFfmpeg()
+		if !ffmpeg.Installed {
+			m.SetErrorKind(domain.ErrorFfmpegMissing)
+			m.SetStatus(domain.Failed)
+			log.Printf("Download failed for %s: %v", m.URL, command.ErrFfmpegMissing)
+			m.AppendLog(fmt.Sprintf("Download failed: %v", command.ErrFfmpegMissing))
+			m.AppendLog("[byto] " + m.ErrorHint)
+			return command.ErrFfmpegMissing
+		}
+		ffmpegPath = ffmpeg.Path
+	}
+
+	err := d.execute(m, ffmpegPath, resume)
+	switch {
+	case err == context.Canceled:
+		// Download was paused, set status to Paused
+		m.SetStatus(domain.Paused)
+		log.Printf("Download paused for %s", m.URL)
+	case err != nil:
+		if m.ErrorKind == domain.ErrorNone {
+			m.SetErrorKind(domain.ErrorUnknown)
+		}
+		m.SetStatus(domain.Failed)
+		log.Printf("Download failed for %s (%s): %v", m.URL, m.ErrorKind, err)
+		m.AppendLog(fmt.Sprintf("Download failed: %v", err))
+		m.AppendLog("[byto] " + m.ErrorHint)
+	default:
+		log.Printf("Download completed: %s", m.URL)
+	}
+	return err
+}
+
+// execute runs yt-dlp for the item. Items without a rate limit of their own
+// take a share of the global limit; when that share changes yt-dlp is
+// interrupted and started again with the new one, continuing its files.
+// The authentication profile is resolved once for all of these runs.
+func (d *Downloader) execute(m *domain.Media, ffmpegPath string, resume bool) error {
+	parent := m.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	defer func() { m.Ctx = parent }()
+
+	if d.usesArchive() {
+		// Keep the archive from being pruned while yt-dlp appends to it
+		d.Archive.Acquire()
+		defer d.Archive.Release()
+	}
+
+	profile, signedIn := d.AuthProfile(m)
+	if signedIn {
+		m.AppendLog("[byto] Signing in with profile: " + profile.Name)
+	}
+
+	shared := m.RateLimit <= 0 && d.Bandwidth != nil
+	if shared {
+		defer d.Bandwidth.Release(m)
+	}
+
+	for {
+		ctx, cancel := context.WithCancelCause(parent)
+		restart := func() { cancel(command.ErrRestart) }
+		m.Ctx = ctx
+
+		b := d.newBuilder(m, profile, signedIn)
+		if ffmpegPath != "" {
+			b = b.FfmpegLocation(ffmpegPath)
+		}
+		if shared {
+			if rate := d.Bandwidth.Acquire(m, restart); rate > 0 {
+				m.AppendLog("[byto] Rate limit: " + domain.FormatRate(rate))
+				b = b.RateLimit(rate)
+			}
+		}
+
+		cmd := &command.DownloadCommand{
+			Builder: b,
+			Resume:  resume,
+		}
+		err := cmd.Execute(m)
+		cancel(nil)
+		if err == context.Canceled && context.Cause(ctx) == command.ErrRestart && parent.Err() == nil {
+			log.Printf("Restarting %s with a new rate limit", m.URL)
+			resume = true
+			continue
+		}
+		return err
+	}
+}
@@ -0,0 +1,159 @@
+package downloader_test
+
+import (
+	"byto/internal/archive"
+	"byto/internal/auth"
+	"byto/internal/domain"
+	"byto/internal/downloader"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newMedia() *domain.Media {
+	return &domain.Media{ID: "1", URL: "http://example.com/video", FilePath: "/downloads", Quality: domain.Quality720p}
+}
+
+func TestNewBuilder_Video(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{}, nil, nil, nil)
+	args := strings.Join(d.NewBuilder(newMedia()).Build(), " ")
+
+	for _, want := range []string{"http://example.com/video", "-o /downloads/", "bestvideo[height<=720]"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in args %q", want, args)
+		}
+	}
+	if strings.Contains(args, "--download-archive") {
+		t.Errorf("expected no download archive when disabled, got %q", args)
+	}
+}
+
+func TestNewBuilder_AudioOnly(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{}, nil, nil, nil)
+	m := newMedia()
+	m.OnlyAudio = true
+	m.Audio = domain.AudioProfile{Codec: domain.AudioMP3, Bitrate: 192}
+	args := strings.Join(d.NewBuilder(m).Build(), " ")
+
+	for _, want := range []string{"-f bestaudio/best", "--extract-audio", "--audio-format mp3", "--audio-quality 192K"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in args %q", want, args)
+		}
+	}
+}
+
+func TestNewBuilder_AudioOnlyFormatID(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{}, nil, nil, nil)
+	m := newMedia()
+	m.OnlyAudio = true
+	m.Format = domain.FormatSelection{FormatID: "140"}
+	args := strings.Join(d.NewBuilder(m).Build(), " ")
+
+	if !strings.Contains(args+" ", "-f 140 ") || strings.Contains(args, "bestaudio") {
+		t.Errorf("expected only the picked audio format, got %q", args)
+	}
+}
+
+func TestNewBuilder_Playlist(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{}, nil, nil, nil)
+	m := newMedia()
+
+	if args := strings.Join(d.NewBuilder(m).Build(), " "); !strings.Contains(args, "--no-playlist") {
+		t.Errorf("expected only the video of a single item, got %q", args)
+	}
+	if args := strings.Join(d.NewProbeBuilder(m).Build(), " "); !strings.Contains(args, "--no-playlist") {
+		t.Errorf("expected only the video of a single item probed, got %q", args)
+	}
+
+	m.IsPlaylist = true
+	m.PlaylistSelection = domain.PlaylistSelection{Type: domain.SelectionItems, Items: "1-3"}
+	args := strings.Join(d.NewBuilder(m).Build(), " ")
+	if strings.Contains(args, "--no-playlist") || !strings.Contains(args, "--playlist-items 1-3") {
+		t.Errorf("expected the selected playlist items, got %q", args)
+	}
+}
+
+func TestNewBuilder_DownloadArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.txt")
+	d := downloader.NewDownloader(&domain.Setting{UseDownloadArchive: true}, nil, archive.NewArchiveAt(path), nil)
+	args := strings.Join(d.NewBuilder(newMedia()).Build(), " ")
+
+	if !strings.Contains(args, "--download-archive "+path) {
+		t.Errorf("expected the archive to be passed, got %q", args)
+	}
+}
+
+func TestNewBuilder_WhileSettingsChange(t *testing.T) {
+	settings := &domain.Setting{}
+	d := downloader.NewDownloader(settings, nil, archive.NewArchiveAt(filepath.Join(t.TempDir(), "archive.txt")), nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			settings.UpdateNetwork(domain.NetworkSettings{ProxyURL: "http://proxy:8080", SocketTimeoutSeconds: i})
+			settings.UpdateDownloadArchive(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		d.NewBuilder(newMedia()).Build()
+	}
+	<-done
+}
+
+func TestNewBuilder_AuthProfile(t *testing.T) {
+	profiles := auth.NewStoreAt(filepath.Join(t.TempDir(), "auth_profiles.json"))
+	site, err := profiles.Save(domain.AuthProfile{Name: "example", Sites: []string{"example.com"}, Browser: "firefox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	explicit, err := profiles.Save(domain.AuthProfile{Name: "file", CookieFile: "/cookies.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := downloader.NewDownloader(&domain.Setting{}, nil, nil, profiles)
+
+	args := strings.Join(d.NewBuilder(newMedia()).Build(), " ")
+	if !strings.Contains(args, "--cookies-from-browser firefox") {
+		t.Errorf("expected the site's profile to be used, got %q", args)
+	}
+
+	m := newMedia()
+	m.AuthProfileID = explicit.ID
+	args = strings.Join(d.NewBuilder(m).Build(), " ")
+	if !strings.Contains(args, "--cookies /cookies.txt") || strings.Contains(args, "--cookies-from-browser") {
+		t.Errorf("expected the item's own profile to win over %s, got %q", site.Name, args)
+	}
+
+	m = newMedia()
+	m.URL = "https://other.org/video"
+	args = strings.Join(d.NewBuilder(m).Build(), " ")
+	if strings.Contains(args, "--cookies") {
+		t.Errorf("expected no cookies for an unmatched site, got %q", args)
+	}
+}
+
+func TestNewBuilder_ItemRateLimit(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{RateLimit: 1 << 20}, nil, nil, nil)
+	m := newMedia()
+	m.RateLimit = 256 * 1024
+	args := strings.Join(d.NewBuilder(m).Build(), " ")
+
+	if !strings.Contains(args, "--limit-rate 262144") {
+		t.Errorf("expected the item's own limit, got %q", args)
+	}
+}
+
+func TestNewBuilder_NetworkSettings(t *testing.T) {
+	d := downloader.NewDownloader(&domain.Setting{Network: domain.NetworkSettings{ProxyURL: "http://proxy:3128", IPVersion: domain.IPv4}}, nil, nil, nil)
+
+	for name, b := range map[string][]string{
+		"download": d.NewBuilder(newMedia()).Build(),
+		"probe":    d.NewProbeBuilder(newMedia()).Build(),
+	} {
+		args := strings.Join(b, " ")
+		if !strings.Contains(args, "--proxy http://proxy:3128") || !strings.Contains(args, "--force-ipv4") {
+			t.Errorf("%s: expected the network settings, got %q", name, args)
+		}
+	}
+}
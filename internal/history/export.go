@@ -0,0 +1,88 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Export writes the entries in the given format
+func Export(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case FormatJSON:
+		return ExportJSON(w, entries)
+	case FormatCSV:
+		return ExportCSV(w, entries)
+	default:
+		return fmt.Errorf("unsupported export format %q, expected %s or %s", format, FormatJSON, FormatCSV)
+	}
+}
+
+// ExportJSON writes the entries as an indented JSON array
+func ExportJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+var csvHeader = []string{"id", "finished_at", "status", "title", "url", "folder", "size", "duration", "quality", "only_audio", "uploader", "extractor", "error_kind", "added_at", "started_at", "files"}
+
+// ExportCSV writes the entries as CSV with a header row. Times use RFC 3339
+// and sizes are in bytes. The files of an entry are separated by "; ".
+func ExportCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		var addedAt, startedAt string
+		if !e.AddedAt.IsZero() {
+			addedAt = e.AddedAt.Format(time.RFC3339)
+		}
+		if e.StartedAt != nil {
+			startedAt = e.StartedAt.Format(time.RFC3339)
+		}
+		quality := e.Options.Quality.String()
+		if e.Options.OnlyAudio {
+			quality = ""
+		}
+
+		record := []string{
+			e.ID,
+			e.FinishedAt.Format(time.RFC3339),
+			e.Status.String(),
+			e.Title,
+			e.URL,
+			e.Folder,
+			strconv.FormatInt(e.Size, 10),
+			strconv.FormatFloat(e.Duration, 'f', -1, 64),
+			quality,
+			strconv.FormatBool(e.Options.OnlyAudio),
+			e.Uploader,
+			e.Extractor,
+			string(e.ErrorKind),
+			addedAt,
+			startedAt,
+			strings.Join(e.Files, "; "),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,332 @@
+package history
+
+import (
+	"byto/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEntryNotFound is returned when no entry has the requested id
+var ErrEntryNotFound = errors.New("history entry not found")
+
+// Options are the download options of an item, kept so an entry can be
+// queued again the way it was downloaded
+type Options struct {
+	Quality           domain.VideoQuality      `json:"quality"`
+	OnlyAudio         bool                     `json:"only_audio"`
+	IsPlaylist        bool                     `json:"is_playlist"`
+	PlaylistSelection domain.PlaylistSelection `json:"playlist_selection,omitempty"`
+	Format            domain.FormatSelection   `json:"format,omitempty"`
+	Subtitles         domain.SubtitleOptions   `json:"subtitles"`
+	Embed             domain.EmbedOptions      `json:"embed"`
+	Audio             domain.AudioProfile      `json:"audio"`
+	OutputTemplate    string                   `json:"output_template,omitempty"`
+	AuthProfileID     string                   `json:"auth_profile_id,omitempty"`
+	RateLimit         int64                    `json:"rate_limit,omitempty"`
+	Priority          domain.Priority          `json:"priority"`
+}
+
+// Entry records a finished download, completed or failed
+type Entry struct {
+	ID      string `json:"id"`
+	MediaID string `json:"media_id"`
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	// Folder is the download folder the item was saved to and Files the
+	// files it produced
+	Folder    string                `json:"folder"`
+	Files     []string              `json:"files,omitempty"`
+	Size      int64                 `json:"size"`
+	Duration  float64               `json:"duration"`
+	Uploader  string                `json:"uploader"`
+	Extractor string                `json:"extractor"`
+	Status    domain.DownloadStatus `json:"status"`
+	ErrorKind domain.ErrorKind      `json:"error_kind,omitempty"`
+	ErrorHint string                `json:"error_hint,omitempty"`
+	// AddedAt is zero for items queued before the history existed
+	AddedAt    time.Time  `json:"added_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time  `json:"finished_at"`
+	Options    Options    `json:"options"`
+}
+
+// NewEntry records the item as it is now, finishing at the given time
+func NewEntry(m *domain.Media, finishedAt time.Time) Entry {
+	return Entry{
+		ID:         uuid.New().String(),
+		MediaID:    m.ID,
+		URL:        m.URL,
+		Title:      m.Title,
+		Folder:     m.FilePath,
+		Files:      slices.Clone(m.OutputFiles),
+		Size:       m.TotalBytes,
+		Duration:   m.Duration,
+		Uploader:   m.Uploader,
+		Extractor:  m.Extractor,
+		Status:     m.Status,
+		ErrorKind:  m.ErrorKind,
+		ErrorHint:  m.ErrorHint,
+		AddedAt:    m.AddedAt,
+		StartedAt:  m.StartedAt,
+		FinishedAt: finishedAt,
+		Options: Options{
+			Quality:           m.Quality,
+			OnlyAudio:         m.OnlyAudio,
+			IsPlaylist:        m.IsPlaylist,
+			PlaylistSelection: m.PlaylistSelection,
+			Format:            m.Format,
+			Subtitles:         m.Subtitles,
+			Embed:             m.Embed,
+			Audio:             m.Audio,
+			OutputTemplate:    m.OutputTemplate,
+			AuthProfileID:     m.AuthProfileID,
+			RateLimit:         m.RateLimit,
+			Priority:          m.Priority,
+		},
+	}
+}
+
+// NewMedia returns a new Pending item for the entry's URL with its original
+// options
+func (e Entry) NewMedia(id string) *domain.Media {
+	return &domain.Media{
+		ID:                id,
+		URL:               e.URL,
+		Title:             e.Title,
+		FilePath:          e.Folder,
+		Quality:           e.Options.Quality,
+		OnlyAudio:         e.Options.OnlyAudio,
+		Status:            domain.Pending,
+		IsPlaylist:        e.Options.IsPlaylist,
+		PlaylistSelection: e.Options.PlaylistSelection,
+		Format:            e.Options.Format,
+		Subtitles:         e.Options.Subtitles,
+		Embed:             e.Options.Embed,
+		Audio:             e.Options.Audio,
+		OutputTemplate:    e.Options.OutputTemplate,
+		AuthProfileID:     e.Options.AuthProfileID,
+		RateLimit:         e.Options.RateLimit,
+		Priority:          e.Options.Priority,
+		AddedAt:           time.Now(),
+		Progress: domain.DownloadProgress{
+			Logs: []string{},
+		},
+	}
+}
+
+// Filter narrows the history down. Empty fields match every entry.
+type Filter struct {
+	// Query matches the title, URL, uploader, folder or file paths,
+	// ignoring case
+	Query    string                  `json:"query"`
+	Statuses []domain.DownloadStatus `json:"statuses"`
+	// From and To bound the time the download finished, both inclusive
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// Matches reports whether the entry passes the filter
+func (f Filter) Matches(e Entry) bool {
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, s := range f.Statuses {
+			if e.Status == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.From != nil && e.FinishedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && e.FinishedAt.After(*f.To) {
+		return false
+	}
+
+	query := strings.ToLower(strings.TrimSpace(f.Query))
+	if query == "" {
+		return true
+	}
+	for _, field := range append([]string{e.Title, e.URL, e.Uploader, e.Folder}, e.Files...) {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps the download history in a JSON file in the byto config
+// directory. The file is read on every call so the desktop app and the
+// command-line interface see each other's downloads.
+type Store struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func getHistoryFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Error getting config dir: %v", err)
+		return "byto_history.json"
+	}
+
+	bytoDir := filepath.Join(configDir, "byto")
+	if err := os.MkdirAll(bytoDir, 0755); err != nil {
+		log.Printf("Error creating config dir: %v", err)
+		return "byto_history.json"
+	}
+
+	return filepath.Join(bytoDir, "history.json")
+}
+
+// NewStore returns the history stored in the byto config directory
+func NewStore() *Store {
+	return NewStoreAt(getHistoryFilePath())
+}
+
+// NewStoreAt returns a history stored at the given file path
+func NewStoreAt(filePath string) *Store {
+	return &Store{
+		filePath: filePath,
+	}
+}
+
+// Record adds a completed, failed or skipped item to the history
+func (s *Store) Record(m *domain.Media) (Entry, error) {
+	if status := m.CurrentStatus(); !status.IsFinished() {
+		return Entry{}, fmt.Errorf("only finished items are recorded, %s is %s", m.ID, status)
+	}
+	entry := NewEntry(m, time.Now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return entry, err
+	}
+	return entry, s.write(append(entries, entry))
+}
+
+// Entries returns the whole history, newest first. A missing file has no
+// entries.
+func (s *Store) Entries() ([]Entry, error) {
+	return s.Search(Filter{})
+}
+
+// Search returns the entries passing the filter, newest first
+func (s *Store) Search(filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	entries, err := s.read()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Entry, 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if filter.Matches(entries[i]) {
+			matches = append(matches, entries[i])
+		}
+	}
+	return matches, nil
+}
+
+// Get returns the entry with the given id
+func (s *Store) Get(id string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, ErrEntryNotFound
+}
+
+// HasURL reports whether the URL was downloaded or tried before
+func (s *Store) HasURL(url string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if e.URL == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Remove deletes the entry with the given id
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if entries[i].ID == id {
+			return s.write(append(entries[:i], entries[i+1:]...))
+		}
+	}
+	return ErrEntryNotFound
+}
+
+// Clear deletes every entry
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write([]Entry{})
+}
+
+func (s *Store) read() ([]Entry, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.filePath, err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file and rename it so a crash mid-write can't leave
+	// a truncated history behind
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.filePath)
+}
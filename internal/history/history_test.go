@@ -0,0 +1,267 @@
+package history_test
+
+import (
+	"bytes"
+	"byto/internal/domain"
+	"byto/internal/history"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTempStore(t *testing.T) *history.Store {
+	t.Helper()
+	return history.NewStoreAt(filepath.Join(t.TempDir(), "history.json"))
+}
+
+func finished(id string, status domain.DownloadStatus) *domain.Media {
+	return &domain.Media{
+		ID:         id,
+		URL:        "https://www.youtube.com/watch?v=" + id,
+		Title:      "Video " + id,
+		FilePath:   "/downloads",
+		Quality:    domain.Quality720p,
+		Status:     status,
+		TotalBytes: 1024,
+		Duration:   61.5,
+		Priority:   domain.PriorityHigh,
+		Subtitles:  domain.SubtitleOptions{Enabled: true, Languages: []string{"en"}},
+		AddedAt:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestEntries_MissingFile_ReturnsEmpty(t *testing.T) {
+	entries, err := newTempStore(t).Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestRecord_PersistsNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	s := history.NewStoreAt(path)
+
+	completed := finished("1", domain.Completed)
+	completed.OutputFiles = []string{"/downloads/Video 1.mp4"}
+	if _, err := s.Record(completed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failed := finished("2", domain.Failed)
+	failed.SetErrorKind(domain.ErrorNetwork)
+	if _, err := s.Record(failed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := history.NewStoreAt(path).Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].MediaID != "2" || entries[1].MediaID != "1" {
+		t.Fatalf("expected both entries newest first, got %+v", entries)
+	}
+	if entries[0].ErrorKind != domain.ErrorNetwork || entries[0].Status != domain.Failed {
+		t.Errorf("expected the failure to be recorded, got %+v", entries[0])
+	}
+	if entries[1].Title != "Video 1" || entries[1].Size != 1024 || entries[1].Options.Quality != domain.Quality720p {
+		t.Errorf("expected the item's details, got %+v", entries[1])
+	}
+	if len(entries[1].Files) != 1 || entries[1].Files[0] != "/downloads/Video 1.mp4" {
+		t.Errorf("expected the output file path, got %v", entries[1].Files)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected no temporary file left behind, got %v", err)
+	}
+}
+
+func TestRecord_SkippedItems(t *testing.T) {
+	s := newTempStore(t)
+	if _, err := s.Record(finished("1", domain.Skipped)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := s.Search(history.Filter{Statuses: []domain.DownloadStatus{domain.Skipped}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Status != domain.Skipped {
+		t.Errorf("expected the skipped item under its own status, got %+v", entries)
+	}
+}
+
+func TestRecord_RejectsUnfinishedItems(t *testing.T) {
+	if _, err := newTempStore(t).Record(finished("1", domain.InProgress)); err == nil {
+		t.Error("expected an error for an item that is still downloading")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newTempStore(t)
+	s.Record(finished("a", domain.Completed))
+	music := finished("b", domain.Completed)
+	music.Title = "Some Song"
+	music.Uploader = "Band"
+	s.Record(music)
+	s.Record(finished("c", domain.Failed))
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	tests := []struct {
+		name   string
+		filter history.Filter
+		want   []string
+	}{
+		{"everything", history.Filter{}, []string{"c", "b", "a"}},
+		{"query title", history.Filter{Query: "song"}, []string{"b"}},
+		{"query uploader", history.Filter{Query: "BAND"}, []string{"b"}},
+		{"query url", history.Filter{Query: "v=a"}, []string{"a"}},
+		{"status", history.Filter{Statuses: []domain.DownloadStatus{domain.Failed}}, []string{"c"}},
+		{"date range", history.Filter{From: &past, To: &future}, []string{"c", "b", "a"}},
+		{"after range", history.Filter{From: &future}, nil},
+		{"before range", history.Filter{To: &past}, nil},
+		{"combined", history.Filter{Query: "video", Statuses: []domain.DownloadStatus{domain.Completed}}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := s.Search(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.MediaID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHasURL(t *testing.T) {
+	s := newTempStore(t)
+	if found, err := s.HasURL("https://www.youtube.com/watch?v=1"); err != nil || found {
+		t.Fatalf("expected an empty history not to have the URL, got %v, %v", found, err)
+	}
+
+	s.Record(finished("1", domain.Completed))
+	if found, _ := s.HasURL("https://www.youtube.com/watch?v=1"); !found {
+		t.Error("expected the recorded URL to be found")
+	}
+	if found, _ := s.HasURL("https://www.youtube.com/watch?v=2"); found {
+		t.Error("expected another URL not to be found")
+	}
+}
+
+func TestRemoveAndClear(t *testing.T) {
+	s := newTempStore(t)
+	first, _ := s.Record(finished("1", domain.Completed))
+	s.Record(finished("2", domain.Completed))
+
+	if err := s.Remove(first.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Remove(first.ID); !errors.Is(err, history.ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+	if _, err := s.Get(first.ID); !errors.Is(err, history.ErrEntryNotFound) {
+		t.Errorf("expected the entry to be gone, got %v", err)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := s.Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries after Clear, got %v", entries)
+	}
+}
+
+func TestEntry_NewMediaKeepsOptions(t *testing.T) {
+	original := finished("1", domain.Completed)
+	original.OnlyAudio = true
+	original.AuthProfileID = "profile"
+	original.RateLimit = 500000
+	original.IsPlaylist = true
+	original.PlaylistSelection = domain.PlaylistSelection{Type: domain.SelectionItems, Items: "1,3"}
+	entry := history.NewEntry(original, time.Now())
+
+	m := entry.NewMedia("new")
+	if m.ID != "new" || m.Status != domain.Pending || m.URL != original.URL || m.FilePath != original.FilePath {
+		t.Errorf("expected a new pending item for the same URL, got %+v", m)
+	}
+	if m.Quality != original.Quality || !m.OnlyAudio || m.AuthProfileID != "profile" || m.RateLimit != 500000 || m.Priority != domain.PriorityHigh {
+		t.Errorf("expected the original options, got %+v", m)
+	}
+	if !m.IsPlaylist || m.PlaylistSelection != original.PlaylistSelection {
+		t.Errorf("expected the playlist selection, got %+v", m.PlaylistSelection)
+	}
+	if !m.Subtitles.Enabled || len(m.Subtitles.Languages) != 1 {
+		t.Errorf("expected the subtitle options, got %+v", m.Subtitles)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	media := finished("1", domain.Completed)
+	media.OutputFiles = []string{"/downloads/a.mp4", "/downloads/b.mp4"}
+	entry := history.NewEntry(media, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	entry.Title = `Title, with "quotes"`
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, []history.Entry{entry}, history.FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header and one row, got %v", records)
+	}
+	row := records[1]
+	if row[1] != "2024-05-01T12:00:00Z" || row[2] != "completed" || row[3] != entry.Title || row[6] != "1024" || row[8] != "720p" {
+		t.Errorf("unexpected row %v", row)
+	}
+	if files := row[len(row)-1]; files != "/downloads/a.mp4; /downloads/b.mp4" {
+		t.Errorf("expected both files, got %q", files)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	entry := history.NewEntry(finished("1", domain.Completed), time.Now())
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, []history.Entry{entry}, history.FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []history.Entry
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].ID != entry.ID {
+		t.Errorf("unexpected export %s", buf.String())
+	}
+
+	buf.Reset()
+	history.ExportJSON(&buf, nil)
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("expected an empty array, got %q", got)
+	}
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	if err := history.Export(&bytes.Buffer{}, nil, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
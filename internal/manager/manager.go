@@ -0,0 +1,239 @@
+package manager
+
+import (
+	"byto/internal/domain"
+	"byto/internal/queue"
+	"log"
+	"sync"
+)
+
+// Manager owns the download workers. It starts the next item, by priority
+// and queue position, whenever a worker is free, and makes sure no item is
+// downloaded by two workers at once.
+//
+// Sites with a limit in SiteLimits never have more downloads running than
+// the limit allows; their items wait while items of other sites keep
+// starting.
+//
+// Items are started when they were requested, e.g. by the Start button, or,
+// once the manager is active, as soon as they are Pending, so items added
+// later are picked up without starting the queue again.
+type Manager struct {
+	queue *queue.Queue
+	run   func(*domain.Media)
+
+	workers    int
+	siteLimits domain.SiteLimits
+	running    int
+	active     bool
+	requested  map[string]bool
+	// claimed holds the items a worker has, by id
+	claimed map[string]*domain.Media
+	// held items aren't started on their own, e.g. while their metadata is
+	// still being fetched
+	held map[string]bool
+	mu   sync.Mutex
+	// released is signalled whenever a worker lets go of an item
+	released *sync.Cond
+	wg       sync.WaitGroup
+}
+
+// NewManager returns a manager running up to workers downloads at a time
+// with run, which downloads one item and returns when it is done
+func NewManager(q *queue.Queue, workers int, run func(*domain.Media)) *Manager {
+	m := &Manager{
+		queue:     q,
+		run:       run,
+		workers:   workers,
+		requested: make(map[string]bool),
+		claimed:   make(map[string]*domain.Media),
+		held:      make(map[string]bool),
+	}
+	m.released = sync.NewCond(&m.mu)
+	return m
+}
+
+// Resize changes the number of workers. Growing starts waiting items right
+// away; shrinking lets running downloads finish and starts no new ones until
+// fewer than n are running.
+func (m *Manager) Resize(n int) {
+	m.mu.Lock()
+	m.workers = n
+	m.mu.Unlock()
+	log.Printf("Download workers set to %d", n)
+	m.dispatch()
+}
+
+// SetSiteLimits changes the per-site caps. Raising or removing a cap starts
+// waiting items of that site right away; lowering one lets running
+// downloads finish.
+func (m *Manager) SetSiteLimits(limits domain.SiteLimits) {
+	m.mu.Lock()
+	m.siteLimits = limits
+	m.mu.Unlock()
+	m.dispatch()
+}
+
+// Activate makes the manager start Pending items on its own, including the
+// ones added from now on
+func (m *Manager) Activate() {
+	m.mu.Lock()
+	m.active = true
+	m.mu.Unlock()
+	m.dispatch()
+}
+
+// Deactivate stops the manager from starting anything new. Running
+// downloads are left alone.
+func (m *Manager) Deactivate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active = false
+	m.requested = make(map[string]bool)
+}
+
+// Request queues items to be downloaded whatever their status, e.g. failed
+// or paused ones
+func (m *Manager) Request(items ...*domain.Media) {
+	m.mu.Lock()
+	for _, item := range items {
+		if m.claimed[item.ID] == nil {
+			m.requested[item.ID] = true
+		}
+	}
+	m.mu.Unlock()
+	m.dispatch()
+}
+
+// Withdraw drops a request for an item that hasn't started yet
+func (m *Manager) Withdraw(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.requested, id)
+}
+
+// Hold keeps a Pending item from being started on its own until Unhold is
+// called. Explicit requests still start it.
+func (m *Manager) Hold(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.held[id] = true
+}
+
+// Unhold lets a held item be started again
+func (m *Manager) Unhold(id string) {
+	m.mu.Lock()
+	delete(m.held, id)
+	m.mu.Unlock()
+	m.dispatch()
+}
+
+// Notify tells the manager the queue changed, e.g. an item was added, so
+// free workers can pick it up
+func (m *Manager) Notify() {
+	m.dispatch()
+}
+
+// RunNow downloads an item right away, even when all workers are busy, and
+// returns when it is done. It is meant for items the user starts by hand:
+// like the workers limit, site limits don't apply. It returns false without
+// running it when another worker already has the item.
+func (m *Manager) RunNow(item *domain.Media) bool {
+	m.mu.Lock()
+	if m.claimed[item.ID] != nil {
+		m.mu.Unlock()
+		return false
+	}
+	m.claim(item)
+	m.mu.Unlock()
+
+	m.work(item)
+	return true
+}
+
+// IsRunning reports whether a worker has the item
+func (m *Manager) IsRunning(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.claimed[id] != nil
+}
+
+// WaitFor blocks until no worker has the item, e.g. until a cancelled
+// download has exited
+func (m *Manager) WaitFor(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.claimed[id] != nil {
+		m.released.Wait()
+	}
+}
+
+// Wait blocks until no download is running
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// dispatch starts waiting items until every worker is busy
+func (m *Manager) dispatch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.running < m.workers {
+		item := m.queue.Next(m.eligible)
+		if item == nil {
+			return
+		}
+		m.claim(item)
+		go m.work(item)
+	}
+}
+
+// eligible reports whether an item may be started next. Callers must hold
+// m.mu.
+func (m *Manager) eligible(item *domain.Media) bool {
+	if m.claimed[item.ID] != nil {
+		return false
+	}
+	if !m.requested[item.ID] && !(m.active && item.CurrentStatus() == domain.Pending && !m.held[item.ID]) {
+		return false
+	}
+	return !m.siteFull(item)
+}
+
+// siteFull reports whether the item's site already has as many downloads
+// running as its limit allows. Callers must hold m.mu.
+func (m *Manager) siteFull(item *domain.Media) bool {
+	limit, ok := m.siteLimits.For(item)
+	if !ok {
+		return false
+	}
+	running := 0
+	for _, other := range m.claimed {
+		if limit.Matches(other) {
+			running++
+		}
+	}
+	return running >= limit.MaxParallel
+}
+
+// claim hands the item to a worker. Callers must hold m.mu.
+func (m *Manager) claim(item *domain.Media) {
+	m.claimed[item.ID] = item
+	delete(m.requested, item.ID)
+	m.running++
+	m.wg.Add(1)
+}
+
+func (m *Manager) work(item *domain.Media) {
+	// Done runs after the next items were claimed, so Wait doesn't return
+	// in between
+	defer m.wg.Done()
+	m.run(item)
+
+	m.mu.Lock()
+	delete(m.claimed, item.ID)
+	m.running--
+	m.released.Broadcast()
+	m.mu.Unlock()
+
+	m.dispatch()
+}
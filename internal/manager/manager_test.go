@@ -0,0 +1,408 @@
+package manager_test
+
+import (
+	"byto/internal/domain"
+	"byto/internal/manager"
+	"byto/internal/queue"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingRunner records started items and keeps each one running until it
+// is finished by the test
+type blockingRunner struct {
+	mu       sync.Mutex
+	started  []string
+	running  map[string]int
+	maxAtOne int
+	done     map[string]chan struct{}
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{
+		running: make(map[string]int),
+		done:    make(map[string]chan struct{}),
+	}
+}
+
+func (r *blockingRunner) channel(id string) chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch, ok := r.done[id]
+	if !ok {
+		ch = make(chan struct{})
+		r.done[id] = ch
+	}
+	return ch
+}
+
+func (r *blockingRunner) run(m *domain.Media) {
+	r.mu.Lock()
+	r.started = append(r.started, m.ID)
+	r.running[m.ID]++
+	if r.running[m.ID] > r.maxAtOne {
+		r.maxAtOne = r.running[m.ID]
+	}
+	r.mu.Unlock()
+
+	<-r.channel(m.ID)
+	m.SetStatus(domain.Completed)
+
+	r.mu.Lock()
+	r.running[m.ID]--
+	r.mu.Unlock()
+}
+
+func (r *blockingRunner) finish(id string) {
+	close(r.channel(id))
+}
+
+func (r *blockingRunner) startedIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.started...)
+}
+
+// waitStarted waits until n items have been started
+func (r *blockingRunner) waitStarted(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if ids := r.startedIDs(); len(ids) >= n {
+			return ids
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d started items, got %v", n, r.startedIDs())
+	return nil
+}
+
+// expectStarted checks that no more than n items start
+func (r *blockingRunner) expectStarted(t *testing.T, n int) {
+	t.Helper()
+	time.Sleep(50 * time.Millisecond)
+	if ids := r.startedIDs(); len(ids) != n {
+		t.Fatalf("expected %d started items, got %v", n, ids)
+	}
+}
+
+func newQueue(ids ...string) *queue.Queue {
+	q := queue.NewQueue()
+	for _, id := range ids {
+		q.Add(&domain.Media{ID: id, Status: domain.Pending})
+	}
+	return q
+}
+
+func TestManager_RespectsWorkerCount(t *testing.T) {
+	q := newQueue("1", "2", "3")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 2, r.run)
+
+	m.Activate()
+	ids := r.waitStarted(t, 2)
+	r.expectStarted(t, 2)
+	if ids[0] != "1" && ids[1] != "1" {
+		t.Errorf("expected the first item to start, got %v", ids)
+	}
+
+	r.finish(ids[0])
+	r.waitStarted(t, 3)
+	r.finish(ids[1])
+	r.finish("3")
+}
+
+func TestManager_InactiveStartsOnlyRequestedItems(t *testing.T) {
+	q := newQueue("1", "2")
+	failed, _ := q.Get("2")
+	failed.Status = domain.Failed
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 2, r.run)
+
+	m.Notify()
+	r.expectStarted(t, 0)
+
+	m.Request(failed)
+	if ids := r.waitStarted(t, 1); ids[0] != "2" {
+		t.Errorf("expected the requested item, got %v", ids)
+	}
+	r.expectStarted(t, 1)
+	r.finish("2")
+}
+
+func TestManager_PicksUpItemsAddedLater(t *testing.T) {
+	q := newQueue()
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+	m.Activate()
+
+	q.Add(&domain.Media{ID: "late", Status: domain.Pending})
+	m.Notify()
+	if ids := r.waitStarted(t, 1); ids[0] != "late" {
+		t.Errorf("expected the new item to start, got %v", ids)
+	}
+	r.finish("late")
+}
+
+func TestManager_HeldItemsWait(t *testing.T) {
+	q := newQueue()
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+	m.Activate()
+
+	m.Hold("1")
+	q.Add(&domain.Media{ID: "1", Status: domain.Pending})
+	m.Notify()
+	r.expectStarted(t, 0)
+
+	m.Unhold("1")
+	r.waitStarted(t, 1)
+	r.finish("1")
+}
+
+func TestManager_ResizeStartsMoreWorkers(t *testing.T) {
+	q := newQueue("1", "2", "3")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+	m.Activate()
+	r.waitStarted(t, 1)
+
+	m.Resize(3)
+	r.waitStarted(t, 3)
+
+	m.Resize(1)
+	q.Add(&domain.Media{ID: "4", Status: domain.Pending})
+	r.finish("1")
+	r.finish("2")
+	r.expectStarted(t, 3)
+	r.finish("3")
+	r.waitStarted(t, 4)
+	r.finish("4")
+}
+
+func TestManager_ByPriority(t *testing.T) {
+	q := newQueue("1", "2", "3")
+	urgent, _ := q.Get("3")
+	urgent.Priority = domain.PriorityHigh
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+
+	m.Activate()
+	if ids := r.waitStarted(t, 1); ids[0] != "3" {
+		t.Errorf("expected the high priority item first, got %v", ids)
+	}
+	r.finish("3")
+	if ids := r.waitStarted(t, 2); ids[1] != "1" {
+		t.Errorf("expected queue order after that, got %v", ids)
+	}
+	r.finish("1")
+	r.finish("2")
+}
+
+func TestManager_AtMostOneWorkerPerItem(t *testing.T) {
+	q := newQueue("1")
+	item, _ := q.Get("1")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 3, r.run)
+
+	m.Request(item)
+	m.Request(item)
+	m.Activate()
+	r.waitStarted(t, 1)
+	if m.RunNow(item) {
+		t.Error("expected RunNow to refuse an item that is running")
+	}
+	if !m.IsRunning("1") {
+		t.Error("expected the item to be running")
+	}
+	r.expectStarted(t, 1)
+
+	r.finish("1")
+	deadline := time.Now().Add(2 * time.Second)
+	for m.IsRunning("1") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if r.maxAtOne != 1 {
+		t.Errorf("expected at most one worker per item, got %d", r.maxAtOne)
+	}
+}
+
+func TestManager_DeactivateDropsRequests(t *testing.T) {
+	q := newQueue("1", "2")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+	first, _ := q.Get("1")
+	second, _ := q.Get("2")
+	second.Status = domain.Paused
+
+	m.Request(first, second)
+	r.waitStarted(t, 1)
+	m.Deactivate()
+	r.finish("1")
+	r.expectStarted(t, 1)
+}
+
+func TestManager_WithdrawDropsRequest(t *testing.T) {
+	q := newQueue("1")
+	item, _ := q.Get("1")
+	item.Status = domain.Failed
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 0, r.run)
+
+	m.Request(item)
+	m.Withdraw("1")
+	m.Resize(1)
+	r.expectStarted(t, 0)
+}
+
+func TestManager_SiteLimitKeepsOtherSitesFlowing(t *testing.T) {
+	q := queue.NewQueue()
+	q.Add(&domain.Media{ID: "yt1", URL: "https://www.youtube.com/watch?v=1", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "yt2", URL: "https://youtube.com/watch?v=2", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "vimeo", URL: "https://vimeo.com/1", Status: domain.Pending})
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 3, r.run)
+	m.SetSiteLimits(domain.SiteLimits{{Site: "youtube.com", MaxParallel: 1}})
+
+	m.Activate()
+	ids := r.waitStarted(t, 2)
+	r.expectStarted(t, 2)
+	if ids[0] == "yt2" || ids[1] == "yt2" {
+		t.Errorf("expected the second youtube item to wait, got %v", ids)
+	}
+
+	r.finish("yt1")
+	if ids := r.waitStarted(t, 3); ids[2] != "yt2" {
+		t.Errorf("expected the second youtube item after the first, got %v", ids)
+	}
+	r.finish("yt2")
+	r.finish("vimeo")
+}
+
+func TestManager_RaisingSiteLimitStartsWaitingItems(t *testing.T) {
+	q := queue.NewQueue()
+	q.Add(&domain.Media{ID: "1", URL: "https://youtu.be/1", Extractor: "youtube", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "2", URL: "https://youtu.be/2", Extractor: "youtube", Status: domain.Pending})
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 2, r.run)
+	m.SetSiteLimits(domain.SiteLimits{{Site: "youtube", MaxParallel: 1}})
+
+	m.Activate()
+	r.waitStarted(t, 1)
+	r.expectStarted(t, 1)
+
+	m.SetSiteLimits(nil)
+	r.waitStarted(t, 2)
+	r.finish("1")
+	r.finish("2")
+}
+
+func TestManager_DispatchWhileItemsChange(t *testing.T) {
+	q := queue.NewQueue()
+	for _, id := range []string{"1", "2", "3", "4"} {
+		q.Add(&domain.Media{ID: id, URL: "https://youtu.be/" + id, Extractor: "youtube", Status: domain.Pending})
+	}
+	items := q.GetAll()
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 2, r.run)
+	m.SetSiteLimits(domain.SiteLimits{{Site: "youtube", MaxParallel: 1}})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			item := items[i%len(items)]
+			item.SetPriority(domain.Priority(i % 3))
+			item.ApplyInfo(domain.MediaInfo{Extractor: "youtube"})
+		}
+	}()
+
+	m.Activate()
+	for n := 1; n <= len(items); n++ {
+		ids := r.waitStarted(t, n)
+		r.finish(ids[n-1])
+	}
+	<-done
+	m.Wait()
+}
+
+func TestManager_Wait(t *testing.T) {
+	q := newQueue("1", "2")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+	first, _ := q.Get("1")
+	second, _ := q.Get("2")
+
+	m.Request(first, second)
+	done := make(chan struct{})
+	go func() {
+		m.Wait()
+		close(done)
+	}()
+
+	r.finish("1")
+	r.waitStarted(t, 2)
+	select {
+	case <-done:
+		t.Fatal("expected Wait to block while an item runs")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r.finish("2")
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Wait to return once every item finished")
+	}
+}
+
+func TestManager_WaitFor(t *testing.T) {
+	q := newQueue("1")
+	item, _ := q.Get("1")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 1, r.run)
+
+	m.WaitFor("1")
+	m.Request(item)
+	r.waitStarted(t, 1)
+
+	done := make(chan struct{})
+	go func() {
+		m.WaitFor("1")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected WaitFor to block while the item runs")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r.finish("1")
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected WaitFor to return once the item finished")
+	}
+}
+
+func TestManager_RequestedItemsRespectLimits(t *testing.T) {
+	q := queue.NewQueue()
+	q.Add(&domain.Media{ID: "running", URL: "https://youtube.com/watch?v=1", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "retry", URL: "https://youtube.com/watch?v=2", Status: domain.Failed})
+	retry, _ := q.Get("retry")
+	r := newBlockingRunner()
+	m := manager.NewManager(q, 2, r.run)
+	m.SetSiteLimits(domain.SiteLimits{{Site: "youtube.com", MaxParallel: 1}})
+	m.Activate()
+	r.waitStarted(t, 1)
+
+	m.Request(retry)
+	r.expectStarted(t, 1)
+
+	r.finish("running")
+	if ids := r.waitStarted(t, 2); ids[1] != "retry" {
+		t.Errorf("expected the retry once the site had room, got %v", ids)
+	}
+	r.finish("retry")
+}
@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// YTDLPArchiveParser recognizes the line yt-dlp prints when it skips a video
+// that is already recorded in the download archive. "title" holds the
+// skipped video's title (or id when yt-dlp doesn't know the title yet, in
+// which case it follows the id with a colon).
+type YTDLPArchiveParser struct{}
+
+var archiveSkipRegex = regexp.MustCompile(`^\[download\]\s+(.+?):?\s+has already been recorded in the archive$`)
+
+func (p YTDLPArchiveParser) Parse(input string) (map[string]string, error) {
+	input = strings.TrimSpace(input)
+	matches := archiveSkipRegex.FindStringSubmatch(input)
+	if len(matches) < 2 {
+		return nil, errors.New("not a download archive line")
+	}
+
+	return map[string]string{"title": strings.TrimSpace(matches[1])}, nil
+}
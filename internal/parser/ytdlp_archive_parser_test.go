@@ -0,0 +1,60 @@
+package parser_test
+
+import (
+	"byto/internal/parser"
+	"testing"
+)
+
+func TestYTDLPArchiveParser_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectTitle string
+		expectError bool
+	}{
+		{
+			name:        "skipped video",
+			input:       "[download] Never Gonna Give You Up has already been recorded in the archive",
+			expectTitle: "Never Gonna Give You Up",
+		},
+		{
+			name:        "skipped by id",
+			input:       "[download] dQw4w9WgXcQ: has already been recorded in the archive",
+			expectTitle: "dQw4w9WgXcQ",
+		},
+		{
+			name:        "surrounding whitespace",
+			input:       "  [download] Clip has already been recorded in the archive  ",
+			expectTitle: "Clip",
+		},
+		{
+			name:        "regular download line",
+			input:       "[download] Destination: /tmp/video.mp4",
+			expectError: true,
+		},
+		{
+			name:        "empty",
+			input:       "",
+			expectError: true,
+		},
+	}
+
+	p := parser.YTDLPArchiveParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result["title"] != tt.expectTitle {
+				t.Errorf("expected title %q, got %q", tt.expectTitle, result["title"])
+			}
+		})
+	}
+}
@@ -10,12 +10,14 @@ type YTDLPDownloadParser struct{}
 
 func (p YTDLPDownloadParser) Parse(input string) (map[string]string, error) {
 	// Format: [byto] <title> [downloaded] <bytes> [total] <bytes|NA> [frag] <index|NA> [frags] <count|NA>
-	// Title is captured between [byto] and [downloaded] markers
-	var logRegex = regexp.MustCompile(`\[byto\]\s+(.+?)\s+\[downloaded\]\s+(\d+|NA)\s+\[total\]\s+(\d+|NA)\s+\[frag\]\s+(\d+|NA)\s+\[frags\]\s+(\d+|NA)$`)
+	//         [speed] <bytes/s|NA> [eta] <seconds|NA> [elapsed] <seconds|NA>
+	// Title is captured between [byto] and [downloaded] markers. The speed
+	// fields are optional and only present in the result when printed.
+	var logRegex = regexp.MustCompile(`\[byto\]\s+(.+?)\s+\[downloaded\]\s+(\d+|NA)\s+\[total\]\s+(\d+|NA)\s+\[frag\]\s+(\d+|NA)\s+\[frags\]\s+(\d+|NA)(?:\s+\[speed\]\s+(\d+(?:\.\d+)?|NA)\s+\[eta\]\s+(\d+|NA)\s+\[elapsed\]\s+(\d+(?:\.\d+)?|NA))?$`)
 
 	input = strings.TrimSpace(input)
 	matches := logRegex.FindStringSubmatch(input)
-	if len(matches) < 6 {
+	if len(matches) < 9 {
 		return nil, errors.New("failed to parse log line: format mismatch")
 	}
 
@@ -25,6 +27,11 @@ func (p YTDLPDownloadParser) Parse(input string) (map[string]string, error) {
 	result["total_bytes"] = matches[3]
 	result["fragment_index"] = matches[4]
 	result["fragment_count"] = matches[5]
+	if matches[6] != "" {
+		result["speed"] = matches[6]
+		result["eta"] = matches[7]
+		result["elapsed"] = matches[8]
+	}
 
 	return result, nil
 }
@@ -370,6 +370,44 @@ func TestYTDLPDownloadParser_Parse(t *testing.T) {
 			expectError:    true,
 		},
 
+		// Speed, ETA and elapsed time
+		{
+			name:  "valid input with speed fields",
+			input: "[byto] Fast Video [downloaded] 1024 [total] 2048 [frag] NA [frags] NA [speed] 524288.5 [eta] 12 [elapsed] 3.25",
+			expectedResult: map[string]string{
+				"title":            "Fast Video",
+				"downloaded_bytes": "1024",
+				"total_bytes":      "2048",
+				"fragment_index":   "NA",
+				"fragment_count":   "NA",
+				"speed":            "524288.5",
+				"eta":              "12",
+				"elapsed":          "3.25",
+			},
+			expectError: false,
+		},
+		{
+			name:  "valid input with NA speed fields",
+			input: "[byto] HLS Video [downloaded] 1024 [total] NA [frag] 3 [frags] 30 [speed] NA [eta] NA [elapsed] 4",
+			expectedResult: map[string]string{
+				"title":            "HLS Video",
+				"downloaded_bytes": "1024",
+				"total_bytes":      "NA",
+				"fragment_index":   "3",
+				"fragment_count":   "30",
+				"speed":            "NA",
+				"eta":              "NA",
+				"elapsed":          "4",
+			},
+			expectError: false,
+		},
+		{
+			name:           "incomplete speed fields",
+			input:          "[byto] Video [downloaded] 100 [total] 200 [frag] 1 [frags] 1 [speed] 100",
+			expectedResult: nil,
+			expectError:    true,
+		},
+
 		// Title containing brackets (but not the marker brackets)
 		{
 			name:  "title containing square brackets",
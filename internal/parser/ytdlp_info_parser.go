@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"byto/internal/domain"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ytdlpInfo mirrors the subset of yt-dlp's --dump-single-json output we use
+type ytdlpInfo struct {
+	Type       string  `json:"_type"`
+	Title      string  `json:"title"`
+	Uploader   string  `json:"uploader"`
+	Channel    string  `json:"channel"`
+	Duration   float64 `json:"duration"`
+	Thumbnail  string  `json:"thumbnail"`
+	Thumbnails []struct {
+		URL string `json:"url"`
+	} `json:"thumbnails"`
+	Filesize         int64 `json:"filesize"`
+	FilesizeApprox   int64 `json:"filesize_approx"`
+	RequestedFormats []struct {
+		Filesize       int64 `json:"filesize"`
+		FilesizeApprox int64 `json:"filesize_approx"`
+	} `json:"requested_formats"`
+	Formats []struct {
+		FormatID       string  `json:"format_id"`
+		Ext            string  `json:"ext"`
+		Resolution     string  `json:"resolution"`
+		Width          int     `json:"width"`
+		Height         int     `json:"height"`
+		FPS            float64 `json:"fps"`
+		VCodec         string  `json:"vcodec"`
+		ACodec         string  `json:"acodec"`
+		DynamicRange   string  `json:"dynamic_range"`
+		Filesize       int64   `json:"filesize"`
+		FilesizeApprox int64   `json:"filesize_approx"`
+		TBR            float64 `json:"tbr"`
+		FormatNote     string  `json:"format_note"`
+	} `json:"formats"`
+	Extractor     string            `json:"extractor"`
+	ExtractorKey  string            `json:"extractor_key"`
+	PlaylistCount int               `json:"playlist_count"`
+	Entries       []json.RawMessage `json:"entries"`
+}
+
+type YTDLPInfoParser struct{}
+
+// ParseInfo converts the JSON printed by yt-dlp -J into a MediaInfo
+func (p YTDLPInfoParser) ParseInfo(data []byte) (domain.MediaInfo, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return domain.MediaInfo{}, errors.New("failed to parse media info: empty output")
+	}
+
+	var raw ytdlpInfo
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return domain.MediaInfo{}, err
+	}
+
+	info := domain.MediaInfo{
+		Title:        strings.TrimSpace(raw.Title),
+		Uploader:     raw.Uploader,
+		Duration:     raw.Duration,
+		ThumbnailURL: raw.Thumbnail,
+		Extractor:    raw.Extractor,
+	}
+	if info.Uploader == "" {
+		info.Uploader = raw.Channel
+	}
+	if info.ThumbnailURL == "" && len(raw.Thumbnails) > 0 {
+		// yt-dlp sorts thumbnails by preference, best last
+		info.ThumbnailURL = raw.Thumbnails[len(raw.Thumbnails)-1].URL
+	}
+	if info.Extractor == "" {
+		info.Extractor = strings.ToLower(raw.ExtractorKey)
+	}
+
+	if raw.Type == "playlist" || raw.Type == "multi_video" {
+		info.IsPlaylist = true
+		info.PlaylistCount = raw.PlaylistCount
+		if info.PlaylistCount == 0 {
+			info.PlaylistCount = len(raw.Entries)
+		}
+		return info, nil
+	}
+
+	info.Formats = make([]domain.FormatInfo, 0, len(raw.Formats))
+	for _, f := range raw.Formats {
+		if f.FormatID == "" {
+			continue
+		}
+		size := f.Filesize
+		if size == 0 {
+			size = f.FilesizeApprox
+		}
+		info.Formats = append(info.Formats, domain.FormatInfo{
+			FormatID:     f.FormatID,
+			Ext:          f.Ext,
+			Resolution:   f.Resolution,
+			Width:        f.Width,
+			Height:       f.Height,
+			FPS:          f.FPS,
+			VCodec:       f.VCodec,
+			ACodec:       f.ACodec,
+			DynamicRange: f.DynamicRange,
+			Filesize:     size,
+			Bitrate:      f.TBR,
+			Note:         f.FormatNote,
+		})
+	}
+
+	// Merged downloads report each stream separately
+	if len(raw.RequestedFormats) > 0 {
+		for _, f := range raw.RequestedFormats {
+			if f.Filesize > 0 {
+				info.EstimatedSize += f.Filesize
+			} else {
+				info.EstimatedSize += f.FilesizeApprox
+			}
+		}
+	} else if raw.Filesize > 0 {
+		info.EstimatedSize = raw.Filesize
+	} else {
+		info.EstimatedSize = raw.FilesizeApprox
+	}
+
+	return info, nil
+}
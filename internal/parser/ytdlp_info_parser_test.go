@@ -0,0 +1,194 @@
+package parser_test
+
+import (
+	"byto/internal/parser"
+	"testing"
+)
+
+func TestYTDLPInfoParser_ParseInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectError      bool
+		expectTitle      string
+		expectUploader   string
+		expectDuration   float64
+		expectThumbnail  string
+		expectSize       int64
+		expectExtractor  string
+		expectIsPlaylist bool
+		expectCount      int
+	}{
+		{
+			name:            "single video with filesize",
+			input:           `{"_type":"video","title":"My Video","uploader":"Someone","duration":212,"thumbnail":"https://i.ytimg.com/vi/x/max.jpg","filesize":1048576,"extractor":"youtube"}`,
+			expectTitle:     "My Video",
+			expectUploader:  "Someone",
+			expectDuration:  212,
+			expectThumbnail: "https://i.ytimg.com/vi/x/max.jpg",
+			expectSize:      1048576,
+			expectExtractor: "youtube",
+		},
+		{
+			name:            "merged formats sum their sizes",
+			input:           `{"title":"Merged","requested_formats":[{"filesize":1000},{"filesize_approx":500}],"filesize_approx":99}`,
+			expectTitle:     "Merged",
+			expectSize:      1500,
+			expectExtractor: "",
+		},
+		{
+			name:        "approximate size fallback",
+			input:       `{"title":"Approx","filesize_approx":2048}`,
+			expectTitle: "Approx",
+			expectSize:  2048,
+		},
+		{
+			name:            "thumbnail falls back to last thumbnails entry",
+			input:           `{"title":"T","thumbnails":[{"url":"low.jpg"},{"url":"high.jpg"}]}`,
+			expectTitle:     "T",
+			expectThumbnail: "high.jpg",
+		},
+		{
+			name:           "uploader falls back to channel",
+			input:          `{"title":"T","channel":"Channel Name"}`,
+			expectTitle:    "T",
+			expectUploader: "Channel Name",
+		},
+		{
+			name:            "extractor falls back to lowercased extractor_key",
+			input:           `{"title":"T","extractor_key":"Vimeo"}`,
+			expectTitle:     "T",
+			expectExtractor: "vimeo",
+		},
+		{
+			name:             "playlist with playlist_count",
+			input:            `{"_type":"playlist","title":"My List","uploader":"Someone","playlist_count":12,"entries":[{},{}],"extractor":"youtube:tab"}`,
+			expectTitle:      "My List",
+			expectUploader:   "Someone",
+			expectExtractor:  "youtube:tab",
+			expectIsPlaylist: true,
+			expectCount:      12,
+		},
+		{
+			name:             "playlist count falls back to entries",
+			input:            `{"_type":"playlist","title":"L","entries":[{},{},{}]}`,
+			expectTitle:      "L",
+			expectIsPlaylist: true,
+			expectCount:      3,
+		},
+		{
+			name:             "multi_video is treated as playlist",
+			input:            `{"_type":"multi_video","title":"Parts","entries":[{}]}`,
+			expectTitle:      "Parts",
+			expectIsPlaylist: true,
+			expectCount:      1,
+		},
+		{
+			name:        "title is trimmed",
+			input:       "  {\"title\":\"  Spaced  \"}\n",
+			expectTitle: "Spaced",
+		},
+		{
+			name:        "empty input",
+			input:       "",
+			expectError: true,
+		},
+		{
+			name:        "whitespace only",
+			input:       "  \n ",
+			expectError: true,
+		},
+		{
+			name:        "invalid JSON",
+			input:       `{"title":`,
+			expectError: true,
+		},
+		{
+			name:        "non-object JSON",
+			input:       `[1,2,3]`,
+			expectError: true,
+		},
+	}
+
+	p := parser.YTDLPInfoParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := p.ParseInfo([]byte(tt.input))
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got nil (info: %+v)", info)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.Title != tt.expectTitle {
+				t.Errorf("Title = %q, want %q", info.Title, tt.expectTitle)
+			}
+			if info.Uploader != tt.expectUploader {
+				t.Errorf("Uploader = %q, want %q", info.Uploader, tt.expectUploader)
+			}
+			if info.Duration != tt.expectDuration {
+				t.Errorf("Duration = %v, want %v", info.Duration, tt.expectDuration)
+			}
+			if info.ThumbnailURL != tt.expectThumbnail {
+				t.Errorf("ThumbnailURL = %q, want %q", info.ThumbnailURL, tt.expectThumbnail)
+			}
+			if info.EstimatedSize != tt.expectSize {
+				t.Errorf("EstimatedSize = %d, want %d", info.EstimatedSize, tt.expectSize)
+			}
+			if info.Extractor != tt.expectExtractor {
+				t.Errorf("Extractor = %q, want %q", info.Extractor, tt.expectExtractor)
+			}
+			if info.IsPlaylist != tt.expectIsPlaylist {
+				t.Errorf("IsPlaylist = %v, want %v", info.IsPlaylist, tt.expectIsPlaylist)
+			}
+			if info.PlaylistCount != tt.expectCount {
+				t.Errorf("PlaylistCount = %d, want %d", info.PlaylistCount, tt.expectCount)
+			}
+		})
+	}
+}
+
+func TestYTDLPInfoParser_ParseInfo_Formats(t *testing.T) {
+	input := `{"title":"V","formats":[
+		{"format_id":"140","ext":"m4a","resolution":"audio only","vcodec":"none","acodec":"mp4a.40.2","filesize":3000,"tbr":129.5,"format_note":"medium"},
+		{"format_id":"137","ext":"mp4","resolution":"1920x1080","width":1920,"height":1080,"fps":30,"vcodec":"avc1.640028","acodec":"none","dynamic_range":"SDR","filesize_approx":90000},
+		{"ext":"mp4"}
+	]}`
+
+	info, err := parser.YTDLPInfoParser{}.ParseInfo([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(info.Formats) != 2 {
+		t.Fatalf("expected 2 formats (entry without id skipped), got %d", len(info.Formats))
+	}
+
+	audio := info.Formats[0]
+	if audio.FormatID != "140" || audio.Ext != "m4a" || audio.Filesize != 3000 || audio.Bitrate != 129.5 || audio.Note != "medium" {
+		t.Errorf("unexpected audio format: %+v", audio)
+	}
+	if audio.HasVideo() || !audio.HasAudio() {
+		t.Errorf("expected audio-only format, got %+v", audio)
+	}
+
+	video := info.Formats[1]
+	if video.Height != 1080 || video.Width != 1920 || video.FPS != 30 || video.DynamicRange != "SDR" {
+		t.Errorf("unexpected video format: %+v", video)
+	}
+	if video.Filesize != 90000 {
+		t.Errorf("expected approximate size fallback, got %d", video.Filesize)
+	}
+}
+
+func TestYTDLPInfoParser_ParseInfo_PlaylistHasNoFormats(t *testing.T) {
+	info, err := parser.YTDLPInfoParser{}.ParseInfo([]byte(`{"_type":"playlist","title":"L","entries":[]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(info.Formats) != 0 {
+		t.Errorf("expected no formats for playlist, got %d", len(info.Formats))
+	}
+}
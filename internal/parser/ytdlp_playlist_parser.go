@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// YTDLPPlaylistParser recognizes the line yt-dlp prints when it moves on to
+// the next video of a playlist, e.g. "[download] Downloading item 3 of 10".
+// "index" holds the 1-based position and "count" the number of videos.
+type YTDLPPlaylistParser struct{}
+
+var playlistItemRegex = regexp.MustCompile(`^\[download\]\s+Downloading (?:item|video) (\d+) of (\d+)$`)
+
+func (p YTDLPPlaylistParser) Parse(input string) (map[string]string, error) {
+	input = strings.TrimSpace(input)
+	matches := playlistItemRegex.FindStringSubmatch(input)
+	if len(matches) < 3 {
+		return nil, errors.New("not a playlist item line")
+	}
+
+	return map[string]string{
+		"index": matches[1],
+		"count": matches[2],
+	}, nil
+}
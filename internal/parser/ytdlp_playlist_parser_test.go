@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"byto/internal/parser"
+	"testing"
+)
+
+func TestYTDLPPlaylistParser_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectIndex string
+		expectCount string
+		expectError bool
+	}{
+		{
+			name:        "item line",
+			input:       "[download] Downloading item 3 of 10",
+			expectIndex: "3",
+			expectCount: "10",
+		},
+		{
+			name:        "older video line",
+			input:       "[download] Downloading video 1 of 2",
+			expectIndex: "1",
+			expectCount: "2",
+		},
+		{
+			name:        "surrounding whitespace",
+			input:       "  [download] Downloading item 12 of 120 ",
+			expectIndex: "12",
+			expectCount: "120",
+		},
+		{
+			name:        "playlist header",
+			input:       "[youtube:tab] Playlist Mix: Downloading 10 items of 10",
+			expectError: true,
+		},
+		{
+			name:        "finished playlist",
+			input:       "[download] Finished downloading playlist: Mix",
+			expectError: true,
+		},
+		{
+			name:        "empty",
+			input:       "",
+			expectError: true,
+		},
+	}
+
+	p := parser.YTDLPPlaylistParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result["index"] != tt.expectIndex || result["count"] != tt.expectCount {
+				t.Errorf("expected %s of %s, got %s of %s", tt.expectIndex, tt.expectCount, result["index"], result["count"])
+			}
+		})
+	}
+}
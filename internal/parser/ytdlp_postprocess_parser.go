@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// YTDLPPostProcessParser recognizes lines printed by yt-dlp's post-processors
+// (merging, converting, embedding) once the download itself is done.
+// "processor" holds the post-processor name and "message" the rest of the line.
+type YTDLPPostProcessParser struct{}
+
+var postProcessRegex = regexp.MustCompile(`^\[(Merger|ExtractAudio|Metadata|EmbedThumbnail|EmbedSubtitle|ThumbnailsConvertor|SubtitlesConvertor|VideoConvertor|VideoRemuxer|ModifyChapters|SponsorBlock|SplitChapters|Fixup\w+|FFmpeg\w+)\]\s*(.*)$`)
+
+func (p YTDLPPostProcessParser) Parse(input string) (map[string]string, error) {
+	input = strings.TrimSpace(input)
+	matches := postProcessRegex.FindStringSubmatch(input)
+	if len(matches) < 3 {
+		return nil, errors.New("not a post-processing line")
+	}
+
+	return map[string]string{
+		"processor": matches[1],
+		"message":   strings.TrimSpace(matches[2]),
+	}, nil
+}
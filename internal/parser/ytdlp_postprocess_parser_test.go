@@ -0,0 +1,99 @@
+package parser_test
+
+import (
+	"byto/internal/parser"
+	"testing"
+)
+
+func TestYTDLPPostProcessParser_Parse(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectProcessor string
+		expectMessage   string
+		expectError     bool
+	}{
+		{
+			name:            "merger",
+			input:           `[Merger] Merging formats into "/tmp/video.mp4"`,
+			expectProcessor: "Merger",
+			expectMessage:   `Merging formats into "/tmp/video.mp4"`,
+		},
+		{
+			name:            "extract audio",
+			input:           "[ExtractAudio] Destination: /tmp/song.mp3",
+			expectProcessor: "ExtractAudio",
+			expectMessage:   "Destination: /tmp/song.mp3",
+		},
+		{
+			name:            "embed thumbnail",
+			input:           `[EmbedThumbnail] ffmpeg: Adding thumbnail to "/tmp/song.mp3"`,
+			expectProcessor: "EmbedThumbnail",
+			expectMessage:   `ffmpeg: Adding thumbnail to "/tmp/song.mp3"`,
+		},
+		{
+			name:            "metadata",
+			input:           `[Metadata] Adding metadata to "/tmp/video.mp4"`,
+			expectProcessor: "Metadata",
+			expectMessage:   `Adding metadata to "/tmp/video.mp4"`,
+		},
+		{
+			name:            "fixup",
+			input:           "[FixupM3u8] Fixing MPEG-TS in MP4 container of \"x.mp4\"",
+			expectProcessor: "FixupM3u8",
+			expectMessage:   "Fixing MPEG-TS in MP4 container of \"x.mp4\"",
+		},
+		{
+			name:            "thumbnail conversion",
+			input:           "[ThumbnailsConvertor] Converting thumbnail \"x.webp\" to jpg",
+			expectProcessor: "ThumbnailsConvertor",
+			expectMessage:   "Converting thumbnail \"x.webp\" to jpg",
+		},
+		{
+			name:        "download line",
+			input:       "[download] Destination: /tmp/video.f137.mp4",
+			expectError: true,
+		},
+		{
+			name:        "youtube extractor line",
+			input:       "[youtube] abc: Downloading webpage",
+			expectError: true,
+		},
+		{
+			name:        "progress line",
+			input:       "[byto] Title [downloaded] 1 [total] 2 [frag] NA [frags] NA",
+			expectError: true,
+		},
+		{
+			name:        "processor name in the middle",
+			input:       "WARNING: [Merger] something",
+			expectError: true,
+		},
+	}
+
+	p := parser.YTDLPPostProcessParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result["processor"] != tt.expectProcessor {
+				t.Errorf("processor = %q, want %q", result["processor"], tt.expectProcessor)
+			}
+			if result["message"] != tt.expectMessage {
+				t.Errorf("message = %q, want %q", result["message"], tt.expectMessage)
+			}
+		})
+	}
+}
+
+func TestYTDLPPostProcessParser_ImplementsParserInterface(t *testing.T) {
+	var _ parser.Parser = parser.YTDLPPostProcessParser{}
+}
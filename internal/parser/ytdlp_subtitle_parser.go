@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// YTDLPSubtitleParser recognizes yt-dlp's subtitle-related output lines.
+// The "event" key is one of written, converted, embedded or missing; "path"
+// holds the file involved when yt-dlp reports one.
+type YTDLPSubtitleParser struct{}
+
+var (
+	subtitleWrittenRegex  = regexp.MustCompile(`^\[info\]\s+Writing video (?:automatic )?subtitles to:\s+(.+)$`)
+	subtitleEmbeddedRegex = regexp.MustCompile(`^\[EmbedSubtitle\]\s+Embedding subtitles in\s+"?(.+?)"?$`)
+)
+
+func (p YTDLPSubtitleParser) Parse(input string) (map[string]string, error) {
+	input = strings.TrimSpace(input)
+
+	if matches := subtitleWrittenRegex.FindStringSubmatch(input); len(matches) == 2 {
+		return map[string]string{"event": "written", "path": strings.TrimSpace(matches[1])}, nil
+	}
+	if matches := subtitleEmbeddedRegex.FindStringSubmatch(input); len(matches) == 2 {
+		return map[string]string{"event": "embedded", "path": strings.TrimSpace(matches[1])}, nil
+	}
+	if strings.HasPrefix(input, "[SubtitlesConvertor]") {
+		return map[string]string{"event": "converted", "path": ""}, nil
+	}
+	if strings.Contains(input, "no subtitles for the requested languages") {
+		return map[string]string{"event": "missing", "path": ""}, nil
+	}
+	return nil, errors.New("not a subtitle line")
+}
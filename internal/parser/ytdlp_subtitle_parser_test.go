@@ -0,0 +1,92 @@
+package parser_test
+
+import (
+	"byto/internal/parser"
+	"testing"
+)
+
+func TestYTDLPSubtitleParser_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectEvent string
+		expectPath  string
+		expectError bool
+	}{
+		{
+			name:        "subtitles written",
+			input:       "[info] Writing video subtitles to: /tmp/My_Video.en.vtt",
+			expectEvent: "written",
+			expectPath:  "/tmp/My_Video.en.vtt",
+		},
+		{
+			name:        "automatic subtitles written",
+			input:       "[info] Writing video automatic subtitles to: C:\\Downloads\\clip.ar.vtt",
+			expectEvent: "written",
+			expectPath:  "C:\\Downloads\\clip.ar.vtt",
+		},
+		{
+			name:        "subtitles embedded with quotes",
+			input:       `[EmbedSubtitle] Embedding subtitles in "/tmp/My_Video.mp4"`,
+			expectEvent: "embedded",
+			expectPath:  "/tmp/My_Video.mp4",
+		},
+		{
+			name:        "subtitles converted",
+			input:       "[SubtitlesConvertor] Converting subtitles",
+			expectEvent: "converted",
+		},
+		{
+			name:        "no subtitles",
+			input:       "[info] There are no subtitles for the requested languages",
+			expectEvent: "missing",
+		},
+		{
+			name:        "surrounding whitespace",
+			input:       "   [info] Writing video subtitles to: a.srt  ",
+			expectEvent: "written",
+			expectPath:  "a.srt",
+		},
+		{
+			name:        "progress line",
+			input:       "[byto] Title [downloaded] 1 [total] 2 [frag] NA [frags] NA",
+			expectError: true,
+		},
+		{
+			name:        "other info line",
+			input:       "[info] Downloading 1 format(s): 22",
+			expectError: true,
+		},
+		{
+			name:        "empty",
+			input:       "",
+			expectError: true,
+		},
+	}
+
+	p := parser.YTDLPSubtitleParser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result["event"] != tt.expectEvent {
+				t.Errorf("event = %q, want %q", result["event"], tt.expectEvent)
+			}
+			if result["path"] != tt.expectPath {
+				t.Errorf("path = %q, want %q", result["path"], tt.expectPath)
+			}
+		})
+	}
+}
+
+func TestYTDLPSubtitleParser_ImplementsParserInterface(t *testing.T) {
+	var _ parser.Parser = parser.YTDLPSubtitleParser{}
+}
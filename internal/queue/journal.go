@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"byto/internal/domain"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JournalLogLines is how many of an item's latest log lines the journal
+// keeps; older ones are only shown until the app closes
+const JournalLogLines = 50
+
+// Journal persists the queue items to disk so pending, paused and failed
+// downloads survive an app restart or crash.
+type Journal struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func getJournalFilePath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Error getting config dir: %v", err)
+		return "byto_queue.json"
+	}
+
+	bytoDir := filepath.Join(configDir, "byto")
+	if err := os.MkdirAll(bytoDir, 0755); err != nil {
+		log.Printf("Error creating config dir: %v", err)
+		return "byto_queue.json"
+	}
+
+	return filepath.Join(bytoDir, "queue.json")
+}
+
+// NewJournal returns a journal stored in the byto config directory.
+func NewJournal() *Journal {
+	return NewJournalAt(getJournalFilePath())
+}
+
+// NewJournalAt returns a journal stored at the given file path.
+func NewJournalAt(filePath string) *Journal {
+	return &Journal{
+		filePath: filePath,
+	}
+}
+
+// FilePath returns the location of the journal file.
+func (j *Journal) FilePath() string {
+	return j.filePath
+}
+
+// Save writes the given items to the journal file. The file is replaced
+// atomically so a crash mid-write never leaves a truncated journal behind.
+// Each item is encoded under its own lock, with its latest JournalLogLines
+// log lines.
+func (j *Journal) Save(items []*domain.Media) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	encoded := make([]json.RawMessage, 0, len(items))
+	for _, media := range items {
+		item, err := media.MarshalJSONWithLogs(JournalLogLines)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, item)
+	}
+	data, err := json.MarshalIndent(encoded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := j.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, j.filePath)
+}
+
+// Load reads the items stored in the journal file. A missing file is not an
+// error and yields an empty list. Items that were still downloading when the
+// journal was written come back as Paused so they can be resumed.
+func (j *Journal) Load() ([]*domain.Media, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := os.ReadFile(j.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*domain.Media{}, nil
+		}
+		return nil, err
+	}
+
+	var items []*domain.Media
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	loaded := make([]*domain.Media, 0, len(items))
+	for _, media := range items {
+		if media == nil || media.ID == "" {
+			continue
+		}
+		if media.Status.IsRunning() {
+			media.Status = domain.Paused
+		}
+		if media.Progress.Logs == nil {
+			media.Progress.Logs = []string{}
+		}
+		loaded = append(loaded, media)
+	}
+	return loaded, nil
+}
@@ -0,0 +1,243 @@
+package queue_test
+
+import (
+	"byto/internal/domain"
+	"byto/internal/queue"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTempJournal(t *testing.T) *queue.Journal {
+	t.Helper()
+	return queue.NewJournalAt(filepath.Join(t.TempDir(), "queue.json"))
+}
+
+func TestJournal_LoadMissingFile_ReturnsEmpty(t *testing.T) {
+	j := newTempJournal(t)
+	items, err := j.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected no items, got %d", len(items))
+	}
+}
+
+func TestJournal_SaveAndLoad_RoundTrip(t *testing.T) {
+	j := newTempJournal(t)
+	items := []*domain.Media{
+		{ID: "1", URL: "http://example.com/a", Title: "A", Status: domain.Pending, Quality: domain.Quality720p},
+		{ID: "2", URL: "http://example.com/b", Title: "B", Status: domain.Failed, OnlyAudio: true},
+	}
+	if err := j.Save(items); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := j.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(loaded))
+	}
+	if loaded[0].ID != "1" || loaded[0].Quality != domain.Quality720p {
+		t.Errorf("unexpected first item: %+v", loaded[0])
+	}
+	if loaded[1].Status != domain.Failed || !loaded[1].OnlyAudio {
+		t.Errorf("unexpected second item: %+v", loaded[1])
+	}
+}
+
+func TestJournal_Save_KeepsLatestLogLines(t *testing.T) {
+	j := newTempJournal(t)
+	media := &domain.Media{ID: "1", Status: domain.Paused}
+	for i := 0; i < queue.JournalLogLines+10; i++ {
+		media.AppendLog(fmt.Sprintf("line %d", i))
+	}
+	if err := j.Save([]*domain.Media{media}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := j.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	logs := loaded[0].Progress.Logs
+	if len(logs) != queue.JournalLogLines || logs[0] != "line 10" {
+		t.Errorf("expected the latest %d lines, got %d starting with %q", queue.JournalLogLines, len(logs), logs[0])
+	}
+	if len(media.Progress.Logs) != queue.JournalLogLines+10 {
+		t.Errorf("expected the item to keep all its logs, got %d", len(media.Progress.Logs))
+	}
+}
+
+func TestJournal_Save_WhileItemChanges(t *testing.T) {
+	j := newTempJournal(t)
+	media := &domain.Media{ID: "1", Status: domain.InProgress}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			media.AppendLog("downloading")
+			media.UpdateProgress(int64(i), 200, i/2)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if err := j.Save([]*domain.Media{media}); err != nil {
+			t.Fatalf("Save error: %v", err)
+		}
+	}
+	<-done
+}
+
+func TestJournal_Load_InProgressBecomesPaused(t *testing.T) {
+	j := newTempJournal(t)
+	if err := j.Save([]*domain.Media{{ID: "1", Status: domain.InProgress}}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := j.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if loaded[0].Status != domain.Paused {
+		t.Errorf("expected Paused, got %d", loaded[0].Status)
+	}
+}
+
+func TestJournal_Load_CompletedUnchanged(t *testing.T) {
+	j := newTempJournal(t)
+	if err := j.Save([]*domain.Media{{ID: "1", Status: domain.Completed}}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, _ := j.Load()
+	if loaded[0].Status != domain.Completed {
+		t.Errorf("expected Completed, got %d", loaded[0].Status)
+	}
+}
+
+func TestJournal_Load_NilLogsInitialized(t *testing.T) {
+	j := newTempJournal(t)
+	if err := os.WriteFile(j.FilePath(), []byte(`[{"id":"1","progress":{"logs":null}}]`), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+
+	loaded, err := j.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if loaded[0].Progress.Logs == nil {
+		t.Error("expected non-nil logs slice")
+	}
+}
+
+func TestJournal_Load_SkipsEntriesWithoutID(t *testing.T) {
+	j := newTempJournal(t)
+	if err := os.WriteFile(j.FilePath(), []byte(`[{"id":""},null,{"id":"2"}]`), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+
+	loaded, err := j.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].ID != "2" {
+		t.Errorf("expected only item 2, got %+v", loaded)
+	}
+}
+
+func TestJournal_Load_InvalidJSON_ReturnsError(t *testing.T) {
+	j := newTempJournal(t)
+	if err := os.WriteFile(j.FilePath(), []byte(`[{"id":`), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+
+	if _, err := j.Load(); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestJournal_Save_NoTempFileLeftBehind(t *testing.T) {
+	j := newTempJournal(t)
+	if err := j.Save([]*domain.Media{{ID: "1"}}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if _, err := os.Stat(j.FilePath() + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be renamed away, stat err: %v", err)
+	}
+}
+
+func TestPersistentQueue_RestoresItems(t *testing.T) {
+	j := newTempJournal(t)
+	q := queue.NewPersistentQueue(j)
+	q.Add(&domain.Media{ID: "1", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "2", Status: domain.InProgress})
+
+	restored := queue.NewPersistentQueue(j)
+	items := restored.GetAll()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[1].Status != domain.Paused {
+		t.Errorf("expected in-progress item to be restored as Paused, got %d", items[1].Status)
+	}
+}
+
+func TestPersistentQueue_RemoveIsPersisted(t *testing.T) {
+	j := newTempJournal(t)
+	q := queue.NewPersistentQueue(j)
+	q.Add(&domain.Media{ID: "1"})
+	q.Add(&domain.Media{ID: "2"})
+	if err := q.Remove("1"); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+
+	items := queue.NewPersistentQueue(j).GetAll()
+	if len(items) != 1 || items[0].ID != "2" {
+		t.Errorf("expected only item 2 after reload, got %+v", items)
+	}
+}
+
+func TestPersistentQueue_PersistRecordsItemChanges(t *testing.T) {
+	j := newTempJournal(t)
+	q := queue.NewPersistentQueue(j)
+	m := &domain.Media{ID: "1", Status: domain.Pending}
+	q.Add(m)
+
+	m.SetStatus(domain.Failed)
+	if err := q.Persist(); err != nil {
+		t.Fatalf("Persist error: %v", err)
+	}
+
+	items := queue.NewPersistentQueue(j).GetAll()
+	if items[0].Status != domain.Failed {
+		t.Errorf("expected Failed after reload, got %d", items[0].Status)
+	}
+}
+
+func TestPersistentQueue_NilJournal(t *testing.T) {
+	q := queue.NewPersistentQueue(nil)
+	q.Add(&domain.Media{ID: "1"})
+	if err := q.Persist(); err != nil {
+		t.Errorf("expected nil error without journal, got %v", err)
+	}
+	if len(q.GetAll()) != 1 {
+		t.Error("expected queue to still hold the item")
+	}
+}
+
+func TestPersistentQueue_CorruptJournal_StartsEmpty(t *testing.T) {
+	j := newTempJournal(t)
+	if err := os.WriteFile(j.FilePath(), []byte("not json"), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+
+	q := queue.NewPersistentQueue(j)
+	if len(q.GetAll()) != 0 {
+		t.Error("expected empty queue for corrupt journal")
+	}
+}
@@ -3,12 +3,19 @@ package queue
 import (
 	"byto/internal/domain"
 	"errors"
+	"log"
+	"math"
+	"sort"
 	"sync"
 )
 
+// ErrNotFound is returned when no item has the requested id
+var ErrNotFound = errors.New("media with given ID not found")
+
 type Queue struct {
-	items []*domain.Media
-	mu    sync.Mutex
+	items   []*domain.Media
+	mu      sync.Mutex
+	journal *Journal
 }
 
 func NewQueue() *Queue {
@@ -17,26 +24,119 @@ func NewQueue() *Queue {
 	}
 }
 
+// NewPersistentQueue returns a queue restored from the given journal. Every
+// later Add or Remove is written back to it, and Persist can be called to
+// record changes made to the items themselves.
+func NewPersistentQueue(journal *Journal) *Queue {
+	q := NewQueue()
+	q.journal = journal
+	if journal == nil {
+		return q
+	}
+
+	items, err := journal.Load()
+	if err != nil {
+		log.Printf("Error loading queue journal: %v", err)
+		return q
+	}
+	q.items = items
+	log.Printf("Loaded %d queue items from %s", len(items), journal.FilePath())
+	return q
+}
+
 func (q *Queue) Add(media *domain.Media) {
 	q.mu.Lock()
-	defer q.mu.Unlock()
 	if media.ID == "" {
+		q.mu.Unlock()
 		return
 	}
 	q.items = append(q.items, media)
+	q.mu.Unlock()
+
+	q.Persist()
 }
 
 func (q *Queue) Remove(id string) error {
 	q.mu.Lock()
-	defer q.mu.Unlock()
-
 	for i, media := range q.items {
 		if media.ID == id {
 			q.items = append(q.items[:i], q.items[i+1:]...)
+			q.mu.Unlock()
+			q.Persist()
 			return nil
 		}
 	}
-	return errors.New("media with given ID not found")
+	q.mu.Unlock()
+	return ErrNotFound
+}
+
+// Move puts the item at newIndex, shifting the items in between. Indexes
+// past either end move it to that end.
+func (q *Queue) Move(id string, newIndex int) error {
+	q.mu.Lock()
+	from := -1
+	for i, media := range q.items {
+		if media.ID == id {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		q.mu.Unlock()
+		return ErrNotFound
+	}
+
+	if newIndex < 0 {
+		newIndex = 0
+	}
+	if newIndex > len(q.items)-1 {
+		newIndex = len(q.items) - 1
+	}
+	if newIndex == from {
+		q.mu.Unlock()
+		return nil
+	}
+
+	media := q.items[from]
+	q.items = append(q.items[:from], q.items[from+1:]...)
+	q.items = append(q.items[:newIndex], append([]*domain.Media{media}, q.items[newIndex:]...)...)
+	q.mu.Unlock()
+
+	return q.Persist()
+}
+
+func (q *Queue) MoveToTop(id string) error {
+	return q.Move(id, 0)
+}
+
+func (q *Queue) MoveToBottom(id string) error {
+	return q.Move(id, math.MaxInt)
+}
+
+// Ordered returns the items in the order they should download: by priority,
+// then by queue position
+func (q *Queue) Ordered() []*domain.Media {
+	items := q.GetAll()
+	// Priorities can change while sorting, so compare a snapshot
+	priorities := make(map[*domain.Media]domain.Priority, len(items))
+	for _, media := range items {
+		priorities[media] = media.CurrentPriority()
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return priorities[items[i]] > priorities[items[j]]
+	})
+	return items
+}
+
+// Next returns the first item in download order that eligible accepts, or
+// nil when there is none
+func (q *Queue) Next(eligible func(*domain.Media) bool) *domain.Media {
+	for _, media := range q.Ordered() {
+		if eligible(media) {
+			return media
+		}
+	}
+	return nil
 }
 
 func (q *Queue) GetAll() []*domain.Media {
@@ -55,5 +155,18 @@ func (q *Queue) Get(id string) (*domain.Media, error) {
 			return media, nil
 		}
 	}
-	return nil, errors.New("media not found")
+	return nil, ErrNotFound
+}
+
+// Persist writes the current queue to its journal. It is a no-op for queues
+// created without one.
+func (q *Queue) Persist() error {
+	if q.journal == nil {
+		return nil
+	}
+	if err := q.journal.Save(q.GetAll()); err != nil {
+		log.Printf("Error saving queue journal: %v", err)
+		return err
+	}
+	return nil
 }
@@ -308,3 +308,106 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func newQueueWithIDs(ids ...string) *queue.Queue {
+	q := queue.NewQueue()
+	for _, id := range ids {
+		q.Add(&domain.Media{ID: id})
+	}
+	return q
+}
+
+func queueIDs(items []*domain.Media) []string {
+	ids := make([]string, len(items))
+	for i, m := range items {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		index    int
+		expected []string
+	}{
+		{"forward", "1", 2, []string{"2", "3", "1", "4"}},
+		{"backward", "4", 1, []string{"1", "4", "2", "3"}},
+		{"same place", "2", 1, []string{"1", "2", "3", "4"}},
+		{"past the end", "2", 10, []string{"1", "3", "4", "2"}},
+		{"before the start", "3", -5, []string{"3", "1", "2", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newQueueWithIDs("1", "2", "3", "4")
+			if err := q.Move(tt.id, tt.index); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := queueIDs(q.GetAll())
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMove_UnknownID(t *testing.T) {
+	q := newQueueWithIDs("1")
+	if err := q.Move("missing", 0); err != queue.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMoveToTopAndBottom(t *testing.T) {
+	q := newQueueWithIDs("1", "2", "3")
+	q.MoveToTop("3")
+	q.MoveToBottom("1")
+
+	got := queueIDs(q.GetAll())
+	expected := []string{"3", "2", "1"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestOrdered_ByPriorityThenPosition(t *testing.T) {
+	q := queue.NewQueue()
+	q.Add(&domain.Media{ID: "low", Priority: domain.PriorityLow})
+	q.Add(&domain.Media{ID: "a"})
+	q.Add(&domain.Media{ID: "high"})
+	q.Add(&domain.Media{ID: "b"})
+	high, _ := q.Get("high")
+	high.Priority = domain.PriorityHigh
+
+	got := queueIDs(q.Ordered())
+	expected := []string{"high", "a", "b", "low"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+	// The queue itself keeps its order
+	if ids := queueIDs(q.GetAll()); ids[0] != "low" {
+		t.Errorf("expected GetAll to keep queue order, got %v", ids)
+	}
+}
+
+func TestNext(t *testing.T) {
+	q := queue.NewQueue()
+	q.Add(&domain.Media{ID: "done", Status: domain.Completed, Priority: domain.PriorityHigh})
+	q.Add(&domain.Media{ID: "first", Status: domain.Pending})
+	q.Add(&domain.Media{ID: "urgent", Status: domain.Pending, Priority: domain.PriorityHigh})
+
+	pending := func(m *domain.Media) bool { return m.Status == domain.Pending }
+	if next := q.Next(pending); next == nil || next.ID != "urgent" {
+		t.Errorf("expected the high priority item, got %+v", next)
+	}
+	if next := q.Next(func(*domain.Media) bool { return false }); next != nil {
+		t.Errorf("expected nil, got %+v", next)
+	}
+}
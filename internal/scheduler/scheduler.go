@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"byto/internal/domain"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often the scheduler checks the queue. The window
+// has minute resolution, so checking more often gains nothing.
+const DefaultInterval = 30 * time.Second
+
+// Controller is the part of the app the scheduler drives
+type Controller interface {
+	// Items returns the queue
+	Items() []*domain.Media
+	// Start downloads items that were waiting for their time
+	Start(items []*domain.Media)
+	// Pause stops a running download
+	Pause(m *domain.Media)
+}
+
+// Scheduler starts items once their start time has come and the download
+// window is open, and pauses running downloads when the window closes.
+// Items it holds back get the Scheduled status. Items the user started by
+// hand are overridden: they run whatever the window and their start time.
+type Scheduler struct {
+	controller Controller
+	window     func() domain.DownloadWindow
+	interval   time.Duration
+	// paused holds the ids of the downloads the scheduler paused, so they
+	// become Scheduled rather than Paused
+	paused map[string]struct{}
+	// overridden holds the ids of the items the user started by hand
+	overridden map[string]struct{}
+	mu         sync.Mutex
+	kick       chan struct{}
+	stop       chan struct{}
+}
+
+// NewScheduler returns a scheduler reading the current download window from
+// window on every check
+func NewScheduler(controller Controller, window func() domain.DownloadWindow) *Scheduler {
+	return &Scheduler{
+		controller: controller,
+		window:     window,
+		interval:   DefaultInterval,
+		paused:     make(map[string]struct{}),
+		overridden: make(map[string]struct{}),
+		kick:       make(chan struct{}, 1),
+	}
+}
+
+// Due reports whether an item may download at now
+func (s *Scheduler) Due(m *domain.Media, now time.Time) bool {
+	return s.isOverridden(m.ID) || (m.StartDue(now) && s.window().IsOpen(now))
+}
+
+// Override lets an item the user started by hand run outside the download
+// window and before its start time, until ClearOverride is called
+func (s *Scheduler) Override(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overridden[id] = struct{}{}
+}
+
+// ClearOverride puts the item back under the schedule, e.g. once its
+// download ended
+func (s *Scheduler) ClearOverride(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overridden, id)
+}
+
+func (s *Scheduler) isOverridden(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.overridden[id]
+	return ok
+}
+
+// Tick checks the queue once: it pauses running downloads when the window
+// is closed and starts the scheduled items that are due
+func (s *Scheduler) Tick(now time.Time) {
+	open := s.window().IsOpen(now)
+
+	var due []*domain.Media
+	for _, m := range s.controller.Items() {
+		switch status := m.CurrentStatus(); {
+		case !open && status.IsRunning() && !s.isOverridden(m.ID):
+			s.mu.Lock()
+			s.paused[m.ID] = struct{}{}
+			s.mu.Unlock()
+			log.Printf("Download window closed, pausing %s", m.URL)
+			s.controller.Pause(m)
+		case status == domain.Scheduled && open && m.StartDue(now):
+			due = append(due, m)
+		}
+	}
+
+	if len(due) > 0 {
+		log.Printf("Starting %d scheduled downloads", len(due))
+		s.controller.Start(due)
+	}
+}
+
+// TakePaused reports whether the scheduler paused the item, and forgets it
+func (s *Scheduler) TakePaused(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.paused[id]
+	delete(s.paused, id)
+	return ok
+}
+
+// Kick makes the scheduler check the queue right away, e.g. after the
+// window or an item's start time changed
+func (s *Scheduler) Kick() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the scheduler in the background until Stop is called
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.Tick(time.Now())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			case <-s.kick:
+			}
+			s.Tick(time.Now())
+		}
+	}()
+}
+
+// Stop ends the background checks
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
@@ -0,0 +1,160 @@
+package scheduler_test
+
+import (
+	"byto/internal/domain"
+	"byto/internal/scheduler"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeController struct {
+	mu      sync.Mutex
+	items   []*domain.Media
+	started []*domain.Media
+	paused  []*domain.Media
+}
+
+func (c *fakeController) Items() []*domain.Media {
+	return c.items
+}
+
+func (c *fakeController) Start(items []*domain.Media) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = append(c.started, items...)
+}
+
+func (c *fakeController) Pause(m *domain.Media) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = append(c.paused, m)
+}
+
+var night = domain.DownloadWindow{Enabled: true, Start: "22:00", End: "07:00"}
+
+func at(hour int) time.Time {
+	return time.Date(2024, 3, 10, hour, 0, 0, 0, time.Local)
+}
+
+func newScheduler(c *fakeController, window domain.DownloadWindow) *scheduler.Scheduler {
+	return scheduler.NewScheduler(c, func() domain.DownloadWindow { return window })
+}
+
+func TestTick_PausesRunningDownloadsWhenWindowCloses(t *testing.T) {
+	running := &domain.Media{ID: "1", Status: domain.InProgress}
+	processing := &domain.Media{ID: "2", Status: domain.Processing}
+	c := &fakeController{items: []*domain.Media{running, processing}}
+	s := newScheduler(c, night)
+
+	s.Tick(at(23))
+	if len(c.paused) != 0 {
+		t.Fatalf("expected nothing paused while the window is open, got %d", len(c.paused))
+	}
+
+	s.Tick(at(7))
+	if len(c.paused) != 2 || c.paused[0] != running || c.paused[1] != processing {
+		t.Fatalf("expected the downloading and post-processing items to be paused, got %v", c.paused)
+	}
+	if !s.TakePaused("1") {
+		t.Error("expected the scheduler to remember pausing the item")
+	}
+	if s.TakePaused("1") {
+		t.Error("expected TakePaused to forget the item")
+	}
+}
+
+func TestTick_StartsDueItemsWhenWindowOpens(t *testing.T) {
+	later := at(23)
+	waiting := &domain.Media{ID: "1", Status: domain.Scheduled}
+	notYet := &domain.Media{ID: "2", Status: domain.Scheduled, StartAfter: &later}
+	pending := &domain.Media{ID: "3", Status: domain.Pending}
+	c := &fakeController{items: []*domain.Media{waiting, notYet, pending}}
+	s := newScheduler(c, night)
+
+	s.Tick(at(12))
+	if len(c.started) != 0 {
+		t.Fatalf("expected nothing started while the window is closed, got %d", len(c.started))
+	}
+
+	s.Tick(at(22))
+	if len(c.started) != 1 || c.started[0] != waiting {
+		t.Fatalf("expected only the due scheduled item to start, got %v", c.started)
+	}
+}
+
+func TestTick_StartTimeWithoutWindow(t *testing.T) {
+	start := at(9)
+	m := &domain.Media{ID: "1", Status: domain.Scheduled, StartAfter: &start}
+	c := &fakeController{items: []*domain.Media{m}}
+	s := newScheduler(c, domain.DownloadWindow{})
+
+	s.Tick(at(8))
+	if len(c.started) != 0 {
+		t.Fatal("expected the item to wait for its start time")
+	}
+	s.Tick(at(9))
+	if len(c.started) != 1 {
+		t.Fatal("expected the item to start at its start time")
+	}
+}
+
+func TestDue(t *testing.T) {
+	start := at(23)
+	m := &domain.Media{StartAfter: &start}
+	s := newScheduler(&fakeController{}, night)
+
+	if s.Due(m, at(22)) {
+		t.Error("expected the item to wait for its start time")
+	}
+	if !s.Due(m, at(23)) {
+		t.Error("expected the item to be due")
+	}
+	if s.Due(&domain.Media{}, at(12)) {
+		t.Error("expected nothing to be due while the window is closed")
+	}
+}
+
+func TestOverride_ExemptsItemsStartedByHand(t *testing.T) {
+	start := at(23)
+	manual := &domain.Media{ID: "1", Status: domain.InProgress, StartAfter: &start}
+	other := &domain.Media{ID: "2", Status: domain.InProgress}
+	c := &fakeController{items: []*domain.Media{manual, other}}
+	s := newScheduler(c, night)
+	s.Override("1")
+
+	if !s.Due(manual, at(12)) {
+		t.Error("expected an item started by hand to be due outside the window")
+	}
+	s.Tick(at(12))
+	if len(c.paused) != 1 || c.paused[0] != other {
+		t.Fatalf("expected only the other download paused, got %v", c.paused)
+	}
+
+	s.ClearOverride("1")
+	if s.Due(manual, at(12)) {
+		t.Error("expected the item back under the schedule")
+	}
+}
+
+func TestStart_KickTriggersCheck(t *testing.T) {
+	m := &domain.Media{ID: "1", Status: domain.Scheduled}
+	c := &fakeController{items: []*domain.Media{m}}
+	s := newScheduler(c, domain.DownloadWindow{})
+
+	s.Start()
+	defer s.Stop()
+	s.Kick()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		n := len(c.started)
+		c.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected the background loop to start the scheduled item")
+}
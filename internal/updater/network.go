@@ -0,0 +1,135 @@
+package updater
+
+import (
+	"byto/internal/domain"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ConnectivityCheckURL is fetched by TestConnection. It answers with an
+// empty 204 response.
+var ConnectivityCheckURL = "https://www.youtube.com/generate_204"
+
+// defaultDialTimeout is used when the network settings have no socket timeout
+const defaultDialTimeout = 30 * time.Second
+
+type ConnectionResult struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// newTransport returns a transport tuned for binary downloads that connects
+// as the network settings say. Without a proxy in the settings the
+// environment's proxy variables apply, as they do for yt-dlp.
+func newTransport(network domain.NetworkSettings) (*http.Transport, error) {
+	if err := network.Validate(); err != nil {
+		return nil, err
+	}
+
+	proxyFunc := http.ProxyFromEnvironment
+	proxy, err := network.Proxy()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		proxyFunc = http.ProxyURL(proxy)
+	}
+
+	timeout := defaultDialTimeout
+	if network.SocketTimeoutSeconds > 0 {
+		timeout = time.Duration(network.SocketTimeoutSeconds) * time.Second
+	}
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+	if network.SourceAddress != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(network.SourceAddress)}
+	}
+
+	transport := &http.Transport{
+		Proxy: proxyFunc,
+		DialContext: func(ctx context.Context, netw, addr string) (net.Conn, error) {
+			switch network.IPVersion {
+			case domain.IPv4:
+				netw = "tcp4"
+			case domain.IPv6:
+				netw = "tcp6"
+			}
+			return dialer.DialContext(ctx, netw, addr)
+		},
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+		DisableCompression:  true, // Faster for binary downloads
+		MaxIdleConnsPerHost: 5,
+	}
+	if network.SocketTimeoutSeconds > 0 {
+		transport.ResponseHeaderTimeout = timeout
+	}
+	return transport, nil
+}
+
+// ConfigureNetwork routes the updater's downloads and yt-dlp self-updates
+// through the given network settings
+func (u *Updater) ConfigureNetwork(network domain.NetworkSettings) error {
+	transport, err := newTransport(network)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.httpClient = &http.Client{
+		Timeout:   5 * time.Minute, // Longer timeout for large downloads
+		Transport: transport,
+	}
+	u.network = network
+	return nil
+}
+
+func (u *Updater) client() *http.Client {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.httpClient
+}
+
+func (u *Updater) networkSettings() domain.NetworkSettings {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.network
+}
+
+// TestConnection checks that ConnectivityCheckURL can be reached with the
+// given network settings, which don't have to be saved yet
+func (u *Updater) TestConnection(network domain.NetworkSettings) ConnectionResult {
+	transport, err := newTransport(network)
+	if err != nil {
+		return ConnectionResult{Message: err.Error()}
+	}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	defer transport.CloseIdleConnections()
+
+	start := time.Now()
+	resp, err := client.Get(ConnectivityCheckURL)
+	if err != nil {
+		return ConnectionResult{Message: fmt.Sprintf("Connection failed: %v", err)}
+	}
+	resp.Body.Close()
+	latency := time.Since(start).Milliseconds()
+
+	if resp.StatusCode >= 400 {
+		return ConnectionResult{
+			Message:   fmt.Sprintf("Connection failed: %s", resp.Status),
+			LatencyMs: latency,
+		}
+	}
+	return ConnectionResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Connected in %d ms", latency),
+		LatencyMs: latency,
+	}
+}
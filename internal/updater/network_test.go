@@ -0,0 +1,121 @@
+package updater
+
+import (
+	"byto/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newFakeProxy returns an HTTP proxy that answers every request itself with
+// 204 and counts the requests it saw, with their Proxy-Authorization header
+func newFakeProxy(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Value) {
+	t.Helper()
+	var hits atomic.Int32
+	var auth atomic.Value
+	auth.Store("")
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		auth.Store(r.Header.Get("Proxy-Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, &hits, &auth
+}
+
+func withConnectivityCheckURL(t *testing.T, url string) {
+	t.Helper()
+	orig := ConnectivityCheckURL
+	ConnectivityCheckURL = url
+	t.Cleanup(func() { ConnectivityCheckURL = orig })
+}
+
+// ─── ConfigureNetwork ──────────────────────────────────────────────────────────
+
+func TestConfigureNetwork_RoutesThroughProxy(t *testing.T) {
+	proxy, hits, auth := newFakeProxy(t)
+
+	u := NewUpdater()
+	err := u.ConfigureNetwork(domain.NetworkSettings{ProxyURL: proxy.URL, ProxyUsername: "me", ProxyPassword: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := u.client().Get("http://downloads.example.invalid/file")
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if hits.Load() != 1 {
+		t.Errorf("expected the proxy to see 1 request, got %d", hits.Load())
+	}
+	if got := auth.Load().(string); !strings.HasPrefix(got, "Basic ") {
+		t.Errorf("expected proxy credentials, got %q", got)
+	}
+	if u.client().Timeout == 0 {
+		t.Error("expected non-zero client timeout")
+	}
+}
+
+func TestConfigureNetwork_InvalidSettingsKeepClient(t *testing.T) {
+	u := NewUpdater()
+	before := u.client()
+
+	if err := u.ConfigureNetwork(domain.NetworkSettings{ProxyURL: "ftp://proxy"}); err == nil {
+		t.Fatal("expected an error for an unsupported proxy")
+	}
+	if u.client() != before {
+		t.Error("expected the client to be kept")
+	}
+}
+
+func TestNewTransport_SocketTimeout(t *testing.T) {
+	transport, err := newTransport(domain.NetworkSettings{SocketTimeoutSeconds: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport.ResponseHeaderTimeout.Seconds() != 7 {
+		t.Errorf("expected a 7s response timeout, got %v", transport.ResponseHeaderTimeout)
+	}
+}
+
+// ─── TestConnection ────────────────────────────────────────────────────────────
+
+func TestTestConnection_ThroughProxy(t *testing.T) {
+	proxy, hits, _ := newFakeProxy(t)
+	withConnectivityCheckURL(t, "http://check.example.invalid/generate_204")
+
+	result := NewUpdater().TestConnection(domain.NetworkSettings{ProxyURL: proxy.URL})
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected the check to go through the proxy, got %d requests", hits.Load())
+	}
+}
+
+func TestTestConnection_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	withConnectivityCheckURL(t, server.URL)
+
+	result := NewUpdater().TestConnection(domain.NetworkSettings{})
+	if result.Success {
+		t.Errorf("expected failure, got %+v", result)
+	}
+	if !strings.Contains(result.Message, "403") {
+		t.Errorf("expected the status in the message, got %q", result.Message)
+	}
+}
+
+func TestTestConnection_InvalidSettings(t *testing.T) {
+	result := NewUpdater().TestConnection(domain.NetworkSettings{SourceAddress: "not-an-ip"})
+	if result.Success {
+		t.Errorf("expected failure, got %+v", result)
+	}
+}
@@ -3,6 +3,8 @@ package updater
 import (
 	"archive/tar"
 	"archive/zip"
+	"byto/internal/builder"
+	"byto/internal/domain"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -13,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ulikunitz/xz"
@@ -99,7 +102,7 @@ func (u *Updater) DownloadFfmpeg(progressCallback func(downloaded, total int64))
 	}
 
 	// Download the archive
-	resp, err := u.httpClient.Get(url)
+	resp, err := u.client().Get(url)
 	if err != nil {
 		return fmt.Errorf("failed to download ffmpeg: %w", err)
 	}
@@ -290,16 +293,13 @@ type YtDlpStatus struct {
 type Updater struct {
 	httpClient *http.Client
 	ytdlpPath  string
+	network    domain.NetworkSettings
+	mu         sync.Mutex
 }
 
 func NewUpdater() *Updater {
 	// Create a transport with optimized settings for downloads
-	transport := &http.Transport{
-		MaxIdleConns:        10,
-		IdleConnTimeout:     30 * time.Second,
-		DisableCompression:  true, // Faster for binary downloads
-		MaxIdleConnsPerHost: 5,
-	}
+	transport, _ := newTransport(domain.NetworkSettings{})
 
 	return &Updater{
 		httpClient: &http.Client{
@@ -376,7 +376,7 @@ func (u *Updater) getYtDlpVersion(path string) (string, error) {
 }
 
 func (u *Updater) DownloadYtDlp(progressCallback func(downloaded, total int64)) error {
-	resp, err := u.httpClient.Get(YtDlpReleaseURL)
+	resp, err := u.client().Get(YtDlpReleaseURL)
 	if err != nil {
 		return fmt.Errorf("failed to check yt-dlp releases: %w", err)
 	}
@@ -417,7 +417,7 @@ func (u *Updater) DownloadYtDlp(progressCallback func(downloaded, total int64))
 		return fmt.Errorf("could not find yt-dlp download for %s", runtime.GOOS)
 	}
 
-	dlResp, err := u.httpClient.Get(downloadURL)
+	dlResp, err := u.client().Get(downloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download yt-dlp: %w", err)
 	}
@@ -473,7 +473,7 @@ func (u *Updater) CheckYtDlpUpdate() UpdateResult {
 		}
 	}
 
-	resp, err := u.httpClient.Get(YtDlpReleaseURL)
+	resp, err := u.client().Get(YtDlpReleaseURL)
 	if err != nil {
 		return UpdateResult{
 			Success:        false,
@@ -529,7 +529,7 @@ func (u *Updater) UpdateYTDLP() UpdateResult {
 		}
 	}
 
-	cmd := exec.Command(status.Path, "-U")
+	cmd := exec.Command(status.Path, builder.NewYTDLPBuilder().Network(u.networkSettings()).Update().Build()...)
 	hideWindow(cmd)
 	output, err := cmd.CombinedOutput()
 
@@ -571,7 +571,7 @@ func (u *Updater) CheckAppUpdate() UpdateResult {
 		}
 	}
 
-	resp, err := u.httpClient.Do(req)
+	resp, err := u.client().Do(req)
 	if err != nil {
 		return UpdateResult{
 			Success:        false,
@@ -635,7 +635,7 @@ func (u *Updater) DownloadAppUpdate(downloadURL string, progressCallback func(do
 		return "", fmt.Errorf("no download URL provided")
 	}
 
-	resp, err := u.httpClient.Get(downloadURL)
+	resp, err := u.client().Get(downloadURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to download update: %v", err)
 	}